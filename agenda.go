@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"next-meeting/calendar"
+	"next-meeting/clock"
+	"next-meeting/config"
+	"next-meeting/output"
+)
+
+// runAgenda prints all meetings of today, or of the date given in args
+func runAgenda(ctx context.Context, args []string, cfg *config.Config, clk clock.Clock, onlyAccepted, refresh bool) {
+	now := clk.Now()
+	day := now
+	if len(args) > 0 {
+		var err error
+		if day, err = parseDay(args[0], now); err != nil {
+			errorAndExit("%v\n", err)
+		}
+	}
+
+	var events []*calendar.MeetingInfo
+	var err error
+	if refresh {
+		events, err = fetchEvents(ctx, day, time.Duration(cfg.CacheTTL), clk)
+	} else {
+		events, _, err = loadDayEvents(ctx, day, time.Duration(cfg.CacheTTL), clk)
+	}
+	if err != nil {
+		exitOnLoadError(err, cfg)
+	}
+
+	if onlyAccepted {
+		events = calendar.FilterAccepted(events)
+	}
+
+	entries := output.NewAgenda(events, now)
+	switch outputMode {
+	case outputPlain:
+		fmt.Print(output.AgendaTable(day, entries))
+	case outputMarkdown:
+		fmt.Print(output.AgendaMarkdown(day, entries))
+	case outputJSON:
+		writeJSON(output.NewAgendaJSON(day, entries, now))
+	default:
+		errorAndExit("%v\n", fmt.Errorf("agenda does not support %s output", outputMode))
+	}
+}
+
+// parseDay parses the date given to the agenda command: "today", "tomorrow",
+// "yesterday" or a date like 2026-01-09
+func parseDay(value string, now time.Time) (time.Time, error) {
+	switch strings.ToLower(value) {
+	case "today":
+		return now, nil
+	case "tomorrow":
+		return now.AddDate(0, 0, 1), nil
+	case "yesterday":
+		return now.AddDate(0, 0, -1), nil
+	}
+
+	day, err := time.ParseInLocation(time.DateOnly, value, now.Location())
+	if err != nil {
+		return time.Time{}, errors.New("invalid date, use YYYY-MM-DD, today, tomorrow or yesterday")
+	}
+	return day, nil
+}
@@ -1,55 +1,171 @@
 package cache
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
 	"next-meeting/calendar"
+	"next-meeting/secure"
 )
 
 const (
-	cacheFileName = "next-meeting-cache.json"
-	cacheDuration = 30 * time.Minute
+	cacheDirName = "next-meeting-cache"
+
+	// eventRefreshMargin is how long before a cached meeting starts the cache is
+	// invalidated, so last-minute changes to it are picked up
+	eventRefreshMargin = 2 * time.Minute
 )
 
-// CachedData represents the structure stored in the cache file
+// encrypt controls whether cache entries are encrypted at rest
+var encrypt bool
+
+// SetEncryption enables or disables encryption of cache entries.
+// Encrypted entries are always decrypted on read; while encryption is enabled
+// plain entries are ignored so they get replaced by encrypted ones.
+func SetEncryption(enabled bool) {
+	encrypt = enabled
+}
+
+// Key describes the query that produced a set of cached events.
+// Entries are stored per key so that different accounts, calendars or
+// windows never share cached data.
+type Key struct {
+	Provider  string   `json:"provider"`
+	Profile   string   `json:"profile"`
+	Calendars []string `json:"calendars"`
+	Window    string   `json:"window"` // Local date the events were fetched for (YYYY-MM-DD)
+}
+
+// Hash returns a short stable identifier for the key
+func (k Key) Hash() string {
+	data := fmt.Sprintf("%s|%s|%s|%s", k.Provider, k.Profile, strings.Join(k.Calendars, ","), k.Window)
+	hash := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(hash[:8])
+}
+
+// Equal reports whether two keys describe the same query
+func (k Key) Equal(other Key) bool {
+	return k.Provider == other.Provider && k.Profile == other.Profile &&
+		k.Window == other.Window && slices.Equal(k.Calendars, other.Calendars)
+}
+
+// CachedData represents the structure stored in a cache entry file
 type CachedData struct {
+	Key       Key                     `json:"key"`
 	Timestamp time.Time               `json:"timestamp"`
+	ExpiresAt time.Time               `json:"expires_at"`
 	Events    []*calendar.MeetingInfo `json:"events"`
 }
 
-// GetPath returns the path to the cache file
+// Hash returns the identifier of the entry
+func (c *CachedData) Hash() string {
+	return c.Key.Hash()
+}
+
+// Expired reports whether the entry is no longer valid at the given time
+func (c *CachedData) Expired(now time.Time) bool {
+	return !now.Before(c.ExpiresAt)
+}
+
+// GetPath returns the path to the cache directory
 func GetPath() string {
-	return filepath.Join(os.TempDir(), cacheFileName)
+	return filepath.Join(os.TempDir(), cacheDirName)
+}
+
+func getEntryPath(hash string) string {
+	return filepath.Join(GetPath(), hash+".json")
 }
 
-// Read reads cached events from file.
-// Returns nil if cache doesn't exist or is expired.
-func Read() []*calendar.MeetingInfo {
-	data, err := os.ReadFile(GetPath())
+func readEntry(path string) (*CachedData, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
-		return nil
+		return nil, err
+	}
+
+	if secure.IsSealed(data) {
+		data, err = secure.Open(data)
+		if err != nil {
+			return nil, err
+		}
+	} else if encrypt {
+		return nil, errors.New("cache entry is not encrypted")
 	}
 
 	var cached CachedData
 	if err := json.Unmarshal(data, &cached); err != nil {
+		return nil, err
+	}
+	return &cached, nil
+}
+
+// ReadEntry reads the cache entry for the given key.
+// Returns nil if the entry doesn't exist or is expired at the given time.
+func ReadEntry(key Key, now time.Time) *CachedData {
+	cached, err := readEntry(getEntryPath(key.Hash()))
+	if err != nil {
 		return nil
 	}
 
-	// Check if cache has expired
-	if time.Since(cached.Timestamp) > cacheDuration {
+	// Guard against hash collisions
+	if !cached.Key.Equal(key) {
 		return nil
 	}
 
+	if cached.Expired(now) {
+		return nil
+	}
+
+	return cached
+}
+
+// Read reads cached events for the given key.
+// Returns nil if the entry doesn't exist or is expired at the given time.
+func Read(key Key, now time.Time) []*calendar.MeetingInfo {
+	cached := ReadEntry(key, now)
+	if cached == nil {
+		return nil
+	}
 	return cached.Events
 }
 
-// Write writes events to the cache file
-func Write(events []*calendar.MeetingInfo) error {
+// ExpiresAt returns when events fetched at the given time stop being valid.
+// That is after ttl, at the next local midnight, or shortly before the next
+// meeting starts, whichever comes first.
+func ExpiresAt(fetched time.Time, events []*calendar.MeetingInfo, ttl time.Duration) time.Time {
+	expires := fetched.Add(ttl)
+
+	year, month, day := fetched.Date()
+	midnight := time.Date(year, month, day+1, 0, 0, 0, 0, fetched.Location())
+	if midnight.Before(expires) {
+		expires = midnight
+	}
+
+	for _, event := range events {
+		refresh := event.Start.Add(-eventRefreshMargin)
+		if refresh.After(fetched) && refresh.Before(expires) {
+			expires = refresh
+		}
+	}
+
+	return expires
+}
+
+// Write writes events fetched at the given time to the cache entry for the
+// given key, valid for at most ttl
+func Write(key Key, events []*calendar.MeetingInfo, ttl time.Duration, fetched time.Time) error {
 	cached := CachedData{
-		Timestamp: time.Now(),
+		Key:       key,
+		Timestamp: fetched,
+		ExpiresAt: ExpiresAt(fetched, events, ttl),
 		Events:    events,
 	}
 
@@ -58,14 +174,77 @@ func Write(events []*calendar.MeetingInfo) error {
 		return err
 	}
 
-	return os.WriteFile(GetPath(), data, 0600)
+	if encrypt {
+		data, err = secure.Seal(data)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(GetPath(), 0700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	return os.WriteFile(getEntryPath(key.Hash()), data, 0600)
 }
 
-// Clear deletes the cache file
-func Clear() error {
-	err := os.Remove(GetPath())
-	if os.IsNotExist(err) {
-		return nil // Not an error if file doesn't exist
+// List returns all cache entries, including expired ones, oldest first
+func List() ([]*CachedData, error) {
+	entries, err := os.ReadDir(GetPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var result []*CachedData
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		cached, err := readEntry(filepath.Join(GetPath(), entry.Name()))
+		if err != nil {
+			continue
+		}
+		result = append(result, cached)
 	}
-	return err
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Timestamp.Before(result[j].Timestamp)
+	})
+	return result, nil
+}
+
+// Inspect returns the cache entry whose hash starts with the given prefix
+func Inspect(prefix string) (*CachedData, error) {
+	if prefix == "" {
+		return nil, fmt.Errorf("no cache entry given")
+	}
+
+	entries, err := List()
+	if err != nil {
+		return nil, err
+	}
+
+	var found *CachedData
+	for _, cached := range entries {
+		if !strings.HasPrefix(cached.Hash(), prefix) {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("cache entry %q is ambiguous", prefix)
+		}
+		found = cached
+	}
+
+	if found == nil {
+		return nil, fmt.Errorf("cache entry %q not found", prefix)
+	}
+	return found, nil
+}
+
+// Clear deletes all cache entries
+func Clear() error {
+	return os.RemoveAll(GetPath())
 }
@@ -0,0 +1,359 @@
+package cache
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zalando/go-keyring"
+
+	"next-meeting/calendar"
+)
+
+// useTempCache points the cache directory at a per-test location
+func useTempCache(t *testing.T) {
+	t.Helper()
+	t.Setenv("TMPDIR", t.TempDir())
+}
+
+func testKey(window string) Key {
+	return Key{
+		Provider:  calendar.ProviderName,
+		Profile:   "default",
+		Calendars: []string{calendar.PrimaryCalendarID},
+		Window:    window,
+	}
+}
+
+func TestExpiresAt(t *testing.T) {
+	fetched := time.Date(2026, 1, 9, 14, 0, 0, 0, time.UTC)
+
+	makeMeeting := func(summary string, startOffset time.Duration) *calendar.MeetingInfo {
+		return &calendar.MeetingInfo{
+			Summary: summary,
+			Start:   fetched.Add(startOffset),
+			End:     fetched.Add(startOffset + 30*time.Minute),
+		}
+	}
+
+	tests := []struct {
+		name    string
+		fetched time.Time
+		events  []*calendar.MeetingInfo
+		ttl     time.Duration
+		want    time.Time
+	}{
+		{
+			name:    "no events - ttl applies",
+			fetched: fetched,
+			ttl:     30 * time.Minute,
+			want:    fetched.Add(30 * time.Minute),
+		},
+		{
+			name:    "meeting after ttl - ttl applies",
+			fetched: fetched,
+			events:  []*calendar.MeetingInfo{makeMeeting("Later", 2*time.Hour)},
+			ttl:     30 * time.Minute,
+			want:    fetched.Add(30 * time.Minute),
+		},
+		{
+			name:    "meeting within ttl - expires shortly before it starts",
+			fetched: fetched,
+			events:  []*calendar.MeetingInfo{makeMeeting("Soon", 10*time.Minute)},
+			ttl:     30 * time.Minute,
+			want:    fetched.Add(10*time.Minute - eventRefreshMargin),
+		},
+		{
+			name:    "earliest upcoming meeting wins regardless of order",
+			fetched: fetched,
+			events: []*calendar.MeetingInfo{
+				makeMeeting("Second", 20*time.Minute),
+				makeMeeting("First", 10*time.Minute),
+			},
+			ttl:  30 * time.Minute,
+			want: fetched.Add(10*time.Minute - eventRefreshMargin),
+		},
+		{
+			name:    "meeting already within refresh margin is ignored",
+			fetched: fetched,
+			events: []*calendar.MeetingInfo{
+				makeMeeting("Imminent", eventRefreshMargin/2),
+				makeMeeting("Next", 20*time.Minute),
+			},
+			ttl:  30 * time.Minute,
+			want: fetched.Add(20*time.Minute - eventRefreshMargin),
+		},
+		{
+			name:    "past and ongoing meetings are ignored",
+			fetched: fetched,
+			events: []*calendar.MeetingInfo{
+				makeMeeting("Past", -2*time.Hour),
+				makeMeeting("Ongoing", -10*time.Minute),
+			},
+			ttl:  30 * time.Minute,
+			want: fetched.Add(30 * time.Minute),
+		},
+		{
+			name:    "midnight comes before ttl",
+			fetched: time.Date(2026, 1, 9, 23, 50, 0, 0, time.UTC),
+			ttl:     30 * time.Minute,
+			want:    time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "midnight uses the local time zone of the fetch",
+			fetched: time.Date(2026, 1, 9, 23, 50, 0, 0, time.FixedZone("CET", 3600)),
+			ttl:     time.Hour,
+			want:    time.Date(2026, 1, 10, 0, 0, 0, 0, time.FixedZone("CET", 3600)),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ExpiresAt(tt.fetched, tt.events, tt.ttl)
+			if !got.Equal(tt.want) {
+				t.Errorf("ExpiresAt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadWrite(t *testing.T) {
+	current := time.Date(2026, 1, 9, 14, 0, 0, 0, time.UTC)
+	useTempCache(t)
+
+	key := testKey("2026-01-09")
+	events := []*calendar.MeetingInfo{
+		{Summary: "Standup", Start: current.Add(time.Hour), End: current.Add(90 * time.Minute)},
+	}
+
+	if Read(key, current) != nil {
+		t.Fatalf("expected no cached events initially")
+	}
+
+	if err := Write(key, events, 30*time.Minute, current); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	got := Read(key, current)
+	if len(got) != 1 || got[0].Summary != "Standup" {
+		t.Fatalf("expected cached Standup event, got %+v", got)
+	}
+
+	t.Run("other keys are independent", func(t *testing.T) {
+		if Read(testKey("2026-01-10"), current) != nil {
+			t.Errorf("expected no events for a different window")
+		}
+	})
+
+	t.Run("expires after ttl", func(t *testing.T) {
+		current = current.Add(30 * time.Minute)
+		if Read(key, current) != nil {
+			t.Errorf("expected cache to be expired after ttl")
+		}
+	})
+}
+
+func TestKeysAreIndependent(t *testing.T) {
+	current := time.Date(2026, 1, 9, 14, 0, 0, 0, time.UTC)
+	useTempCache(t)
+
+	key := testKey("2026-01-09")
+	if err := Write(key, []*calendar.MeetingInfo{{Summary: "Standup"}}, time.Hour, current); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	work, personal := key, key
+	work.Profile = "work"
+	personal.Calendars = []string{calendar.PrimaryCalendarID, "family"}
+	for _, other := range []Key{work, personal} {
+		if other.Hash() == key.Hash() {
+			t.Errorf("expected %+v to hash differently from %+v", other, key)
+		}
+		if Read(other, current) != nil {
+			t.Errorf("expected no events for %+v", other)
+		}
+	}
+}
+
+func TestReadEntryKeyMismatch(t *testing.T) {
+	current := time.Date(2026, 1, 9, 14, 0, 0, 0, time.UTC)
+	useTempCache(t)
+
+	// An entry for another key stored under this key's file, as a hash
+	// collision would leave it
+	key, other := testKey("2026-01-09"), testKey("2026-01-10")
+	if err := Write(other, []*calendar.MeetingInfo{{Summary: "Tomorrow"}}, time.Hour, current); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := os.Rename(getEntryPath(other.Hash()), getEntryPath(key.Hash())); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := ReadEntry(key, current); got != nil {
+		t.Errorf("expected the entry of another key to be ignored, got %+v", got.Key)
+	}
+}
+
+func TestList(t *testing.T) {
+	current := time.Date(2026, 1, 9, 14, 0, 0, 0, time.UTC)
+	useTempCache(t)
+
+	if entries, err := List(); err != nil || len(entries) != 0 {
+		t.Fatalf("expected no entries without a cache directory, got %d (err %v)", len(entries), err)
+	}
+
+	if err := Write(testKey("2026-01-10"), nil, time.Hour, current.Add(time.Minute)); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := Write(testKey("2026-01-09"), nil, time.Hour, current); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := os.WriteFile(getEntryPath("broken"), []byte("{not json"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Key.Window != "2026-01-09" || entries[1].Key.Window != "2026-01-10" {
+		t.Fatalf("expected both entries oldest first, skipping unreadable ones, got %d", len(entries))
+	}
+}
+
+func TestReadInvalidatesAtMidnight(t *testing.T) {
+	current := time.Date(2026, 1, 9, 23, 45, 0, 0, time.UTC)
+	useTempCache(t)
+
+	key := testKey("2026-01-09")
+	if err := Write(key, nil, time.Hour, current); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	current = time.Date(2026, 1, 9, 23, 59, 59, 0, time.UTC)
+	entries, err := List()
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("expected one cache entry, got %d (err %v)", len(entries), err)
+	}
+	if entries[0].Expired(current) {
+		t.Errorf("expected entry to be valid before midnight")
+	}
+
+	current = time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	if !entries[0].Expired(current) {
+		t.Errorf("expected entry to be expired at midnight")
+	}
+}
+
+func TestReadInvalidatesBeforeMeeting(t *testing.T) {
+	current := time.Date(2026, 1, 9, 14, 0, 0, 0, time.UTC)
+	useTempCache(t)
+
+	key := testKey("2026-01-09")
+	events := []*calendar.MeetingInfo{
+		{Summary: "Moved Meeting", Start: current.Add(15 * time.Minute), End: current.Add(45 * time.Minute)},
+	}
+	if err := Write(key, events, time.Hour, current); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	current = current.Add(15*time.Minute - eventRefreshMargin - time.Second)
+	if Read(key, current) == nil {
+		t.Errorf("expected cache to be valid before the refresh margin")
+	}
+
+	current = current.Add(time.Second)
+	if Read(key, current) != nil {
+		t.Errorf("expected cache to be invalidated shortly before the meeting starts")
+	}
+}
+
+func TestInspect(t *testing.T) {
+	current := time.Date(2026, 1, 9, 14, 0, 0, 0, time.UTC)
+	useTempCache(t)
+
+	key := testKey("2026-01-09")
+	if err := Write(key, nil, time.Hour, current); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	entry, err := Inspect(key.Hash()[:4])
+	if err != nil {
+		t.Fatalf("Inspect failed: %v", err)
+	}
+	if entry.Key.Window != "2026-01-09" {
+		t.Errorf("expected window 2026-01-09, got %q", entry.Key.Window)
+	}
+
+	if _, err := Inspect("zzzz"); err == nil {
+		t.Errorf("expected error for unknown entry")
+	}
+}
+
+func TestEncryption(t *testing.T) {
+	keyring.MockInit()
+	current := time.Date(2026, 1, 9, 14, 0, 0, 0, time.UTC)
+	useTempCache(t)
+	SetEncryption(true)
+	t.Cleanup(func() { SetEncryption(false) })
+
+	key := testKey("2026-01-09")
+	events := []*calendar.MeetingInfo{
+		{Summary: "Confidential HR Review", Start: current.Add(time.Hour), End: current.Add(2 * time.Hour)},
+	}
+	if err := Write(key, events, time.Hour, current); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	t.Run("entry is not stored in plain text", func(t *testing.T) {
+		data, err := os.ReadFile(getEntryPath(key.Hash()))
+		if err != nil {
+			t.Fatalf("failed to read cache entry: %v", err)
+		}
+		if strings.Contains(string(data), "Confidential") {
+			t.Errorf("expected cache entry to be encrypted, got %q", data)
+		}
+	})
+
+	t.Run("entry is decrypted on read", func(t *testing.T) {
+		got := Read(key, current)
+		if len(got) != 1 || got[0].Summary != "Confidential HR Review" {
+			t.Errorf("expected decrypted event, got %+v", got)
+		}
+	})
+
+	t.Run("encrypted entry is readable with encryption disabled", func(t *testing.T) {
+		SetEncryption(false)
+		defer SetEncryption(true)
+		if Read(key, current) == nil {
+			t.Errorf("expected encrypted entry to be decrypted transparently")
+		}
+	})
+
+	t.Run("corrupted entry is a miss", func(t *testing.T) {
+		path := getEntryPath(key.Hash())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("failed to read cache entry: %v", err)
+		}
+		data[len(data)-1] ^= 0xff
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			t.Fatalf("failed to corrupt cache entry: %v", err)
+		}
+		if Read(key, current) != nil {
+			t.Errorf("expected corrupted entry to be treated as a miss")
+		}
+	})
+
+	t.Run("plain entry is a miss while encryption is enabled", func(t *testing.T) {
+		SetEncryption(false)
+		if err := Write(key, events, time.Hour, current); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		SetEncryption(true)
+		if Read(key, current) != nil {
+			t.Errorf("expected plain entry to be ignored")
+		}
+	})
+}
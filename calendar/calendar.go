@@ -4,14 +4,27 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
+	"next-meeting/clock"
+
 	"google.golang.org/api/calendar/v3"
 	"google.golang.org/api/option"
 )
 
+const (
+	// ProviderName identifies the calendar backend events are fetched from
+	ProviderName = "google"
+	// PrimaryCalendarID is the calendar events are fetched from
+	PrimaryCalendarID = "primary"
+)
+
 // MeetingInfo contains information about a calendar event
 type MeetingInfo struct {
+	ID                 string // Calendar event ID, stable when the event is edited or moved
 	Summary            string
 	Start              time.Time
 	End                time.Time
@@ -19,41 +32,51 @@ type MeetingInfo struct {
 	Location           string
 	HangoutLink        string // Google Meet / Hangout link if available
 	Attendees          int
-	SelfResponseStatus string // The current user's response status (accepted, declined, tentative, needsAction)
+	SelfResponseStatus string          // The current user's response status (accepted, declined, tentative, needsAction)
+	Conference         string          // Name of the conferencing service, e.g. "Google Meet" or "Zoom"
+	Transparency       string          // "transparent" if the event doesn't block time, otherwise "opaque" or empty
+	Reminders          []time.Duration // Popup reminders set on the event or its calendar, largest first
+}
+
+// IsBusy reports whether the meeting blocks the user's time.
+// Declined and transparent events leave the user free.
+func (m *MeetingInfo) IsBusy() bool {
+	return m.SelfResponseStatus != "declined" && m.Transparency != "transparent"
 }
 
 // MeetingStatus represents the current meeting status
 type MeetingStatus struct {
 	CurrentMeeting *MeetingInfo
 	NextMeeting    *MeetingInfo
+	Upcoming       []*MeetingInfo // All meetings that have not started yet, earliest first
+	Parallel       []*MeetingInfo // Other meetings overlapping the current one, earliest first
 }
 
 // Service wraps the Google Calendar API service
 type Service struct {
-	svc *calendar.Service
+	svc   *calendar.Service
+	clock clock.Clock
 }
 
-// NewService creates a new Calendar service
-func NewService(ctx context.Context, client *http.Client) (*Service, error) {
+// NewService creates a new Calendar service that uses clk to decide what "today" is
+func NewService(ctx context.Context, client *http.Client, clk clock.Clock) (*Service, error) {
 	svc, err := calendar.NewService(ctx, option.WithHTTPClient(client))
 	if err != nil {
 		return nil, fmt.Errorf("unable to create calendar service: %w", err)
 	}
-	return &Service{svc: svc}, nil
+	return &Service{svc: svc, clock: clk}, nil
 }
 
-// GetTodayEvents fetches all events for today from the primary calendar.
-func (s *Service) GetTodayEvents(ctx context.Context) ([]*MeetingInfo, error) {
-	now := time.Now()
-
-	// Query events from now onwards, limited to today
-	year, month, day := now.Date()
-	tomorrow := time.Date(year, month, day+1, 0, 0, 0, 0, now.Location())
+// GetDayEvents fetches all events on the local day containing t from the primary calendar.
+func (s *Service) GetDayEvents(ctx context.Context, t time.Time) ([]*MeetingInfo, error) {
+	year, month, day := t.Date()
+	dayStart := time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+	dayEnd := time.Date(year, month, day+1, 0, 0, 0, 0, t.Location())
 
-	timeMin := now.Add(-2 * time.Hour).Format(time.RFC3339) // Include events that may have started recently
-	timeMax := tomorrow.Format(time.RFC3339)
+	timeMin := dayStart.Format(time.RFC3339)
+	timeMax := dayEnd.Format(time.RFC3339)
 
-	events, err := s.svc.Events.List("primary").
+	events, err := s.svc.Events.List(PrimaryCalendarID).
 		ShowDeleted(false).
 		SingleEvents(true).
 		TimeMin(timeMin).
@@ -84,6 +107,7 @@ func (s *Service) GetTodayEvents(ctx context.Context) ([]*MeetingInfo, error) {
 		}
 
 		meeting := &MeetingInfo{
+			ID:                 item.Id,
 			Summary:            item.Summary,
 			Start:              start,
 			End:                end,
@@ -91,6 +115,9 @@ func (s *Service) GetTodayEvents(ctx context.Context) ([]*MeetingInfo, error) {
 			HangoutLink:        item.HangoutLink,
 			Attendees:          len(item.Attendees),
 			SelfResponseStatus: getSelfResponseStatus(item),
+			Conference:         getConference(item),
+			Transparency:       item.Transparency,
+			Reminders:          getReminders(item, events.DefaultReminders),
 		}
 
 		result = append(result, meeting)
@@ -99,6 +126,26 @@ func (s *Service) GetTodayEvents(ctx context.Context) ([]*MeetingInfo, error) {
 	return result, nil
 }
 
+// getReminders returns how long before the event its popup reminders fire,
+// largest first. Events without their own reminders use the calendar's
+// defaults; email reminders are ignored.
+func getReminders(event *calendar.Event, defaults []*calendar.EventReminder) []time.Duration {
+	reminders := defaults
+	if event.Reminders != nil && !event.Reminders.UseDefault {
+		reminders = event.Reminders.Overrides
+	}
+
+	var offsets []time.Duration
+	for _, reminder := range reminders {
+		if reminder.Method == "popup" {
+			offsets = append(offsets, time.Duration(reminder.Minutes)*time.Minute)
+		}
+	}
+	slices.Sort(offsets)
+	slices.Reverse(offsets)
+	return slices.Compact(offsets)
+}
+
 // getSelfResponseStatus returns the current user's response status for an event
 func getSelfResponseStatus(event *calendar.Event) string {
 	// Events without attendees are considered accepted (user-created events)
@@ -120,6 +167,46 @@ func getSelfResponseStatus(event *calendar.Event) string {
 	return ""
 }
 
+// conferenceHosts maps URL hosts to the name of their conferencing service
+var conferenceHosts = []struct {
+	host string
+	name string
+}{
+	{"meet.google.com", "Google Meet"},
+	{"zoom.us", "Zoom"},
+	{"teams.microsoft.com", "Microsoft Teams"},
+	{"teams.live.com", "Microsoft Teams"},
+	{"webex.com", "Webex"},
+	{"whereby.com", "Whereby"},
+	{"meet.jit.si", "Jitsi Meet"},
+}
+
+// getConference returns the name of the conferencing service used by an event
+func getConference(event *calendar.Event) string {
+	if event.ConferenceData != nil && event.ConferenceData.ConferenceSolution != nil &&
+		event.ConferenceData.ConferenceSolution.Name != "" {
+		return event.ConferenceData.ConferenceSolution.Name
+	}
+	for _, text := range []string{event.HangoutLink, event.Location, event.Description} {
+		if name := ConferenceFromText(text); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// ConferenceFromText returns the name of the conferencing service whose link
+// appears in text, or "" if there is none
+func ConferenceFromText(text string) string {
+	text = strings.ToLower(text)
+	for _, c := range conferenceHosts {
+		if strings.Contains(text, c.host) {
+			return c.name
+		}
+	}
+	return ""
+}
+
 // FilterAccepted returns only events where the user has accepted or tentatively accepted (maybe)
 func FilterAccepted(events []*MeetingInfo) []*MeetingInfo {
 	var result []*MeetingInfo
@@ -149,6 +236,7 @@ func GetMeetingStatus(events []*MeetingInfo, now time.Time) *MeetingStatus {
 		// Future meeting: earliest upcoming, or if same start time, prefer shorter
 		// This includes meetings that start during the current meeting
 		if now.Before(meeting.Start) {
+			status.Upcoming = append(status.Upcoming, meeting)
 			if status.NextMeeting == nil ||
 				meeting.Start.Before(status.NextMeeting.Start) ||
 				(meeting.Start.Equal(status.NextMeeting.Start) && meeting.End.Before(status.NextMeeting.End)) {
@@ -157,9 +245,93 @@ func GetMeetingStatus(events []*MeetingInfo, now time.Time) *MeetingStatus {
 		}
 	}
 
+	sort.SliceStable(status.Upcoming, func(i, j int) bool {
+		a, b := status.Upcoming[i], status.Upcoming[j]
+		if a.Start.Equal(b.Start) {
+			return a.End.Before(b.End)
+		}
+		return a.Start.Before(b.Start)
+	})
+
+	if current := status.CurrentMeeting; current != nil && current.IsBusy() {
+		for _, meeting := range events {
+			if meeting != current && meeting.IsBusy() && Overlaps(meeting, current) {
+				status.Parallel = append(status.Parallel, meeting)
+			}
+		}
+		sort.SliceStable(status.Parallel, func(i, j int) bool {
+			return status.Parallel[i].Start.Before(status.Parallel[j].Start)
+		})
+	}
+
 	return status
 }
 
+// Ongoing returns the current meeting followed by the parallel meetings that
+// have already started
+func (s *MeetingStatus) Ongoing() []*MeetingInfo {
+	if s.CurrentMeeting == nil {
+		return nil
+	}
+	ongoing := []*MeetingInfo{s.CurrentMeeting}
+	for _, meeting := range s.Parallel {
+		// The current meeting started most recently, so later ones are upcoming
+		if !meeting.Start.After(s.CurrentMeeting.Start) {
+			ongoing = append(ongoing, meeting)
+		}
+	}
+	return ongoing
+}
+
+// Overlaps reports whether two meetings share any time. Back-to-back meetings don't overlap.
+func Overlaps(a, b *MeetingInfo) bool {
+	return a.Start.Before(b.End) && b.Start.Before(a.End)
+}
+
+// Conflict is a pair of meetings that overlap
+type Conflict struct {
+	A *MeetingInfo
+	B *MeetingInfo
+}
+
+// Overlap returns the time both meetings of the conflict take place
+func (c Conflict) Overlap() Interval {
+	overlap := Interval{Start: c.A.Start, End: c.A.End}
+	if c.B.Start.After(overlap.Start) {
+		overlap.Start = c.B.Start
+	}
+	if c.B.End.Before(overlap.End) {
+		overlap.End = c.B.End
+	}
+	return overlap
+}
+
+// ConflictPairs returns every pair of overlapping meetings once, ordered by
+// when the overlap starts. Declined and transparent meetings never conflict.
+func ConflictPairs(events []*MeetingInfo) []Conflict {
+	var conflicts []Conflict
+	for i, a := range events {
+		if !a.IsBusy() {
+			continue
+		}
+		for _, b := range events[i+1:] {
+			if !b.IsBusy() || !Overlaps(a, b) {
+				continue
+			}
+			if b.Start.Before(a.Start) {
+				conflicts = append(conflicts, Conflict{A: b, B: a})
+			} else {
+				conflicts = append(conflicts, Conflict{A: a, B: b})
+			}
+		}
+	}
+
+	sort.SliceStable(conflicts, func(i, j int) bool {
+		return conflicts[i].Overlap().Start.Before(conflicts[j].Overlap().Start)
+	})
+	return conflicts
+}
+
 // FormatDuration returns a human-readable duration string
 func FormatDuration(d time.Duration) string {
 	if d < 0 {
@@ -2,8 +2,11 @@ package calendar
 
 import (
 	"fmt"
+	"slices"
 	"testing"
 	"time"
+
+	"google.golang.org/api/calendar/v3"
 )
 
 func TestGetMeetingStatus(t *testing.T) {
@@ -823,3 +826,319 @@ func TestFilterAccepted_WithMeetingStatusIntegration(t *testing.T) {
 		t.Errorf("Second filtered event should be 'Maybe Next', got %q", filtered[1].Summary)
 	}
 }
+
+func TestGetMeetingStatus_Upcoming(t *testing.T) {
+	fixedNow := time.Date(2026, 1, 9, 14, 30, 0, 0, time.UTC)
+
+	makeMeeting := func(summary string, startOffset, endOffset time.Duration) *MeetingInfo {
+		return &MeetingInfo{
+			Summary: summary,
+			Start:   fixedNow.Add(startOffset),
+			End:     fixedNow.Add(endOffset),
+		}
+	}
+
+	tests := []struct {
+		name          string
+		events        []*MeetingInfo
+		wantSummaries []string
+	}{
+		{
+			name:          "no events",
+			events:        nil,
+			wantSummaries: nil,
+		},
+		{
+			name: "past and current meetings are not upcoming",
+			events: []*MeetingInfo{
+				makeMeeting("Past", -2*time.Hour, -1*time.Hour),
+				makeMeeting("Current", -10*time.Minute, 10*time.Minute),
+				makeMeeting("Starting Now", 0, 30*time.Minute),
+			},
+			wantSummaries: nil,
+		},
+		{
+			name: "sorted by start time",
+			events: []*MeetingInfo{
+				makeMeeting("Third", 3*time.Hour, 4*time.Hour),
+				makeMeeting("First", 1*time.Hour, 2*time.Hour),
+				makeMeeting("Second", 2*time.Hour, 3*time.Hour),
+			},
+			wantSummaries: []string{"First", "Second", "Third"},
+		},
+		{
+			name: "same start - shorter first",
+			events: []*MeetingInfo{
+				makeMeeting("Long", 1*time.Hour, 3*time.Hour),
+				makeMeeting("Short", 1*time.Hour, 90*time.Minute),
+			},
+			wantSummaries: []string{"Short", "Long"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := GetMeetingStatus(tt.events, fixedNow)
+			if len(status.Upcoming) != len(tt.wantSummaries) {
+				t.Fatalf("expected %d upcoming meetings, got %d", len(tt.wantSummaries), len(status.Upcoming))
+			}
+			for i, meeting := range status.Upcoming {
+				if meeting.Summary != tt.wantSummaries[i] {
+					t.Errorf("Upcoming[%d].Summary = %q, want %q", i, meeting.Summary, tt.wantSummaries[i])
+				}
+			}
+			if len(status.Upcoming) > 0 && status.Upcoming[0] != status.NextMeeting {
+				t.Errorf("expected first upcoming meeting to be NextMeeting")
+			}
+		})
+	}
+}
+
+func TestConferenceFromText(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"empty", "", ""},
+		{"google meet", "https://meet.google.com/abc-defg-hij", "Google Meet"},
+		{"zoom subdomain", "Join: https://acme.zoom.us/j/123456", "Zoom"},
+		{"teams", "https://teams.microsoft.com/l/meetup-join/xyz", "Microsoft Teams"},
+		{"case insensitive", "HTTPS://ACME.WEBEX.COM/meet/bob", "Webex"},
+		{"physical room", "Room 4.02", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ConferenceFromText(tt.text); got != tt.want {
+				t.Errorf("ConferenceFromText(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetReminders(t *testing.T) {
+	defaults := []*calendar.EventReminder{
+		{Method: "popup", Minutes: 10},
+		{Method: "email", Minutes: 60},
+	}
+
+	tests := []struct {
+		name      string
+		reminders *calendar.EventReminders
+		want      []time.Duration
+	}{
+		{"calendar defaults", &calendar.EventReminders{UseDefault: true}, []time.Duration{10 * time.Minute}},
+		{"no reminder settings", nil, []time.Duration{10 * time.Minute}},
+		{"overrides", &calendar.EventReminders{Overrides: []*calendar.EventReminder{
+			{Method: "popup", Minutes: 2},
+			{Method: "popup", Minutes: 30},
+			{Method: "email", Minutes: 1440},
+			{Method: "popup", Minutes: 30},
+		}}, []time.Duration{30 * time.Minute, 2 * time.Minute}},
+		{"reminders turned off", &calendar.EventReminders{}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := getReminders(&calendar.Event{Reminders: tt.reminders}, defaults)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("getReminders() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetMeetingStatus_Parallel(t *testing.T) {
+	fixedNow := time.Date(2026, 1, 9, 14, 30, 0, 0, time.UTC)
+
+	makeMeeting := func(summary string, startOffset, endOffset time.Duration, status string) *MeetingInfo {
+		return &MeetingInfo{
+			Summary:            summary,
+			Start:              fixedNow.Add(startOffset),
+			End:                fixedNow.Add(endOffset),
+			SelfResponseStatus: status,
+		}
+	}
+
+	tests := []struct {
+		name          string
+		events        []*MeetingInfo
+		wantCurrent   string
+		wantSummaries []string
+	}{
+		{
+			name: "single meeting",
+			events: []*MeetingInfo{
+				makeMeeting("Sync", -10*time.Minute, 20*time.Minute, "accepted"),
+			},
+			wantCurrent: "Sync",
+		},
+		{
+			name: "two meetings in progress",
+			events: []*MeetingInfo{
+				makeMeeting("Long", -time.Hour, time.Hour, "accepted"),
+				makeMeeting("Sync", -10*time.Minute, 20*time.Minute, "accepted"),
+			},
+			wantCurrent:   "Sync",
+			wantSummaries: []string{"Long"},
+		},
+		{
+			name: "meeting starting during the current one",
+			events: []*MeetingInfo{
+				makeMeeting("Later", time.Hour, 2*time.Hour, "accepted"),
+				makeMeeting("Overlapping", 10*time.Minute, time.Hour, "tentative"),
+				makeMeeting("Sync", -10*time.Minute, 20*time.Minute, "accepted"),
+			},
+			wantCurrent:   "Sync",
+			wantSummaries: []string{"Overlapping"},
+		},
+		{
+			name: "back-to-back meetings are not parallel",
+			events: []*MeetingInfo{
+				makeMeeting("Before", -time.Hour, -10*time.Minute, "accepted"),
+				makeMeeting("Sync", -10*time.Minute, 20*time.Minute, "accepted"),
+				makeMeeting("After", 20*time.Minute, time.Hour, "accepted"),
+			},
+			wantCurrent: "Sync",
+		},
+		{
+			name: "declined meetings are not parallel",
+			events: []*MeetingInfo{
+				makeMeeting("Long", -time.Hour, time.Hour, "declined"),
+				makeMeeting("Sync", -10*time.Minute, 20*time.Minute, "accepted"),
+			},
+			wantCurrent: "Sync",
+		},
+		{
+			name: "transparent events are not parallel",
+			events: []*MeetingInfo{
+				{Summary: "Focus", Start: fixedNow.Add(-time.Hour), End: fixedNow.Add(time.Hour), Transparency: "transparent"},
+				makeMeeting("Sync", -10*time.Minute, 20*time.Minute, "accepted"),
+			},
+			wantCurrent: "Sync",
+		},
+		{
+			name: "not in a meeting",
+			events: []*MeetingInfo{
+				makeMeeting("A", time.Hour, 2*time.Hour, "accepted"),
+				makeMeeting("B", time.Hour, 2*time.Hour, "accepted"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := GetMeetingStatus(tt.events, fixedNow)
+			if tt.wantCurrent == "" {
+				if status.CurrentMeeting != nil {
+					t.Fatalf("expected no current meeting, got %q", status.CurrentMeeting.Summary)
+				}
+			} else if status.CurrentMeeting == nil || status.CurrentMeeting.Summary != tt.wantCurrent {
+				t.Fatalf("expected current meeting %q, got %v", tt.wantCurrent, status.CurrentMeeting)
+			}
+			if len(status.Parallel) != len(tt.wantSummaries) {
+				t.Fatalf("expected %d parallel meetings, got %d", len(tt.wantSummaries), len(status.Parallel))
+			}
+			for i, meeting := range status.Parallel {
+				if meeting.Summary != tt.wantSummaries[i] {
+					t.Errorf("Parallel[%d].Summary = %q, want %q", i, meeting.Summary, tt.wantSummaries[i])
+				}
+			}
+		})
+	}
+}
+
+func TestConflictPairs(t *testing.T) {
+	fixedNow := time.Date(2026, 1, 9, 9, 0, 0, 0, time.UTC)
+
+	makeMeeting := func(summary string, startOffset, endOffset time.Duration, status string) *MeetingInfo {
+		return &MeetingInfo{
+			Summary:            summary,
+			Start:              fixedNow.Add(startOffset),
+			End:                fixedNow.Add(endOffset),
+			SelfResponseStatus: status,
+		}
+	}
+
+	type pair struct {
+		a, b       string
+		start, end time.Duration
+	}
+
+	tests := []struct {
+		name   string
+		events []*MeetingInfo
+		want   []pair
+	}{
+		{
+			name:   "no events",
+			events: nil,
+			want:   nil,
+		},
+		{
+			name: "back-to-back meetings don't conflict",
+			events: []*MeetingInfo{
+				makeMeeting("A", 0, time.Hour, "accepted"),
+				makeMeeting("B", time.Hour, 2*time.Hour, "accepted"),
+			},
+			want: nil,
+		},
+		{
+			name: "pair is ordered by start",
+			events: []*MeetingInfo{
+				makeMeeting("B", 30*time.Minute, 90*time.Minute, "accepted"),
+				makeMeeting("A", 0, time.Hour, "accepted"),
+			},
+			want: []pair{{"A", "B", 30 * time.Minute, time.Hour}},
+		},
+		{
+			name: "pairs are ordered by overlap",
+			events: []*MeetingInfo{
+				makeMeeting("Long", 0, 3*time.Hour, "accepted"),
+				makeMeeting("Late", 2*time.Hour, 150*time.Minute, "tentative"),
+				makeMeeting("Early", 30*time.Minute, time.Hour, "accepted"),
+			},
+			want: []pair{
+				{"Long", "Early", 30 * time.Minute, time.Hour},
+				{"Long", "Late", 2 * time.Hour, 150 * time.Minute},
+			},
+		},
+		{
+			name: "declined meetings are ignored",
+			events: []*MeetingInfo{
+				makeMeeting("A", 0, time.Hour, "accepted"),
+				makeMeeting("Declined", 0, time.Hour, "declined"),
+			},
+			want: nil,
+		},
+		{
+			name: "transparent events are ignored",
+			events: []*MeetingInfo{
+				makeMeeting("A", 0, time.Hour, "accepted"),
+				{Summary: "FYI", Start: fixedNow.Add(30 * time.Minute), End: fixedNow.Add(2 * time.Hour), SelfResponseStatus: "accepted", Transparency: "transparent"},
+			},
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ConflictPairs(tt.events)
+			if len(got) != len(tt.want) {
+				t.Fatalf("ConflictPairs() returned %d conflicts, want %d", len(got), len(tt.want))
+			}
+			for i, conflict := range got {
+				want := tt.want[i]
+				if conflict.A.Summary != want.a || conflict.B.Summary != want.b {
+					t.Errorf("conflict[%d] = %s/%s, want %s/%s", i, conflict.A.Summary, conflict.B.Summary, want.a, want.b)
+				}
+				overlap := conflict.Overlap()
+				if !overlap.Start.Equal(fixedNow.Add(want.start)) || !overlap.End.Equal(fixedNow.Add(want.end)) {
+					t.Errorf("conflict[%d].Overlap() = %v–%v, want %v–%v", i, overlap.Start, overlap.End,
+						fixedNow.Add(want.start), fixedNow.Add(want.end))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package calendar
+
+import (
+	"sort"
+	"time"
+)
+
+// Chain is a run of meetings with no real break between them
+type Chain struct {
+	Interval
+	Meetings []*MeetingInfo // Meetings in the chain, earliest first
+}
+
+// BackToBackChains finds runs of two or more meetings where each meeting
+// starts less than minBreak after the previous ones ended. Declined and
+// transparent events count as breaks.
+func BackToBackChains(events []*MeetingInfo, minBreak time.Duration) []Chain {
+	var busy []*MeetingInfo
+	for _, meeting := range events {
+		if meeting.IsBusy() && meeting.End.After(meeting.Start) {
+			busy = append(busy, meeting)
+		}
+	}
+	sort.SliceStable(busy, func(i, j int) bool {
+		return busy[i].Start.Before(busy[j].Start)
+	})
+
+	var chains []Chain
+	var chain Chain
+	flush := func() {
+		if len(chain.Meetings) > 1 {
+			chains = append(chains, chain)
+		}
+	}
+	for _, meeting := range busy {
+		if len(chain.Meetings) > 0 && meeting.Start.Sub(chain.End) < minBreak {
+			chain.Meetings = append(chain.Meetings, meeting)
+			if meeting.End.After(chain.End) {
+				chain.End = meeting.End
+			}
+			continue
+		}
+		flush()
+		chain = Chain{
+			Interval: Interval{Start: meeting.Start, End: meeting.End},
+			Meetings: []*MeetingInfo{meeting},
+		}
+	}
+	flush()
+
+	return chains
+}
+
+// LongChainAt returns the chain longer than limit that is in progress at now,
+// or otherwise the one that starts next if no meeting comes before it
+func LongChainAt(chains []Chain, limit time.Duration, now time.Time, next *MeetingInfo) *Chain {
+	for i := range chains {
+		chain := &chains[i]
+		if !chain.End.After(now) {
+			continue
+		}
+		if chain.Duration() <= limit {
+			continue
+		}
+		if !chain.Start.After(now) || (next != nil && !chain.Start.After(next.Start)) {
+			return chain
+		}
+		return nil
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackToBackChains(t *testing.T) {
+	base := time.Date(2026, 1, 9, 9, 0, 0, 0, time.UTC)
+
+	makeMeeting := func(summary string, startOffset, endOffset time.Duration) *MeetingInfo {
+		return &MeetingInfo{
+			Summary:            summary,
+			Start:              base.Add(startOffset),
+			End:                base.Add(endOffset),
+			SelfResponseStatus: "accepted",
+		}
+	}
+	declined := func(m *MeetingInfo) *MeetingInfo {
+		m.SelfResponseStatus = "declined"
+		return m
+	}
+	transparent := func(m *MeetingInfo) *MeetingInfo {
+		m.Transparency = "transparent"
+		return m
+	}
+
+	type chain struct {
+		start, end time.Duration
+		summaries  []string
+	}
+
+	tests := []struct {
+		name   string
+		events []*MeetingInfo
+		want   []chain
+	}{
+		{
+			name:   "no events",
+			events: nil,
+			want:   nil,
+		},
+		{
+			name:   "single meeting is not a chain",
+			events: []*MeetingInfo{makeMeeting("A", 0, 3*time.Hour)},
+			want:   nil,
+		},
+		{
+			name: "adjacent meetings",
+			events: []*MeetingInfo{
+				makeMeeting("B", time.Hour, 2*time.Hour),
+				makeMeeting("A", 0, time.Hour),
+			},
+			want: []chain{{0, 2 * time.Hour, []string{"A", "B"}}},
+		},
+		{
+			name: "short gap does not break the chain",
+			events: []*MeetingInfo{
+				makeMeeting("A", 0, 50*time.Minute),
+				makeMeeting("B", time.Hour, 110*time.Minute),
+				makeMeeting("C", 2*time.Hour, 3*time.Hour),
+			},
+			want: []chain{{0, 3 * time.Hour, []string{"A", "B", "C"}}},
+		},
+		{
+			name: "gap of the minimum break splits chains",
+			events: []*MeetingInfo{
+				makeMeeting("A", 0, time.Hour),
+				makeMeeting("B", time.Hour, 2*time.Hour),
+				makeMeeting("C", 135*time.Minute, 3*time.Hour),
+				makeMeeting("D", 3*time.Hour, 4*time.Hour),
+			},
+			want: []chain{
+				{0, 2 * time.Hour, []string{"A", "B"}},
+				{135 * time.Minute, 4 * time.Hour, []string{"C", "D"}},
+			},
+		},
+		{
+			name: "overlapping meetings chain",
+			events: []*MeetingInfo{
+				makeMeeting("A", 0, 2*time.Hour),
+				makeMeeting("B", 30*time.Minute, time.Hour),
+				makeMeeting("C", 2*time.Hour, 150*time.Minute),
+			},
+			want: []chain{{0, 150 * time.Minute, []string{"A", "B", "C"}}},
+		},
+		{
+			name: "declined meeting is a break",
+			events: []*MeetingInfo{
+				makeMeeting("A", 0, time.Hour),
+				declined(makeMeeting("Declined", time.Hour, 2*time.Hour)),
+				makeMeeting("C", 2*time.Hour, 3*time.Hour),
+			},
+			want: nil,
+		},
+		{
+			name: "transparent event is a break",
+			events: []*MeetingInfo{
+				makeMeeting("A", 0, time.Hour),
+				transparent(makeMeeting("Focus", time.Hour, 2*time.Hour)),
+				makeMeeting("C", 2*time.Hour, 3*time.Hour),
+				makeMeeting("D", 3*time.Hour, 4*time.Hour),
+			},
+			want: []chain{{2 * time.Hour, 4 * time.Hour, []string{"C", "D"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BackToBackChains(tt.events, 15*time.Minute)
+			if len(got) != len(tt.want) {
+				t.Fatalf("BackToBackChains() returned %d chains, want %d", len(got), len(tt.want))
+			}
+			for i, c := range got {
+				want := tt.want[i]
+				if !c.Start.Equal(base.Add(want.start)) || !c.End.Equal(base.Add(want.end)) {
+					t.Errorf("chain[%d] = %v–%v, want %v–%v", i, c.Start, c.End, base.Add(want.start), base.Add(want.end))
+				}
+				if len(c.Meetings) != len(want.summaries) {
+					t.Fatalf("chain[%d] has %d meetings, want %d", i, len(c.Meetings), len(want.summaries))
+				}
+				for j, meeting := range c.Meetings {
+					if meeting.Summary != want.summaries[j] {
+						t.Errorf("chain[%d].Meetings[%d] = %q, want %q", i, j, meeting.Summary, want.summaries[j])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestLongChainAt(t *testing.T) {
+	base := time.Date(2026, 1, 9, 9, 0, 0, 0, time.UTC)
+
+	makeMeeting := func(startOffset, endOffset time.Duration) *MeetingInfo {
+		return &MeetingInfo{Start: base.Add(startOffset), End: base.Add(endOffset)}
+	}
+
+	// 09:00–10:00 alone, 11:00–14:00 chained, 15:00–16:00 chained
+	events := []*MeetingInfo{
+		makeMeeting(0, time.Hour),
+		makeMeeting(2*time.Hour, 3*time.Hour),
+		makeMeeting(3*time.Hour, 4*time.Hour),
+		makeMeeting(4*time.Hour, 5*time.Hour),
+		makeMeeting(6*time.Hour, 390*time.Minute),
+		makeMeeting(390*time.Minute, 7*time.Hour),
+	}
+	chains := BackToBackChains(events, 15*time.Minute)
+
+	tests := []struct {
+		name      string
+		now       time.Duration
+		limit     time.Duration
+		wantStart time.Duration
+		wantFound bool
+	}{
+		{name: "before a single meeting", now: -time.Hour, limit: 2 * time.Hour},
+		{name: "between single meeting and chain", now: 90 * time.Minute, limit: 2 * time.Hour, wantStart: 2 * time.Hour, wantFound: true},
+		{name: "inside the chain", now: 210 * time.Minute, limit: 2 * time.Hour, wantStart: 2 * time.Hour, wantFound: true},
+		{name: "chain within the limit", now: 90 * time.Minute, limit: 3 * time.Hour},
+		{name: "before short chain", now: 330 * time.Minute, limit: 2 * time.Hour},
+		{name: "after all chains", now: 8 * time.Hour, limit: 2 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := base.Add(tt.now)
+			status := GetMeetingStatus(events, now)
+			got := LongChainAt(chains, tt.limit, now, status.NextMeeting)
+			if (got != nil) != tt.wantFound {
+				t.Fatalf("LongChainAt() = %v, want found %v", got, tt.wantFound)
+			}
+			if got != nil && !got.Start.Equal(base.Add(tt.wantStart)) {
+				t.Errorf("LongChainAt().Start = %v, want %v", got.Start, base.Add(tt.wantStart))
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package calendar
+
+import (
+	"sort"
+	"time"
+)
+
+// Interval is a span of time from Start (inclusive) to End (exclusive)
+type Interval struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Duration returns the length of the interval
+func (i Interval) Duration() time.Duration {
+	return i.End.Sub(i.Start)
+}
+
+// BusyIntervals merges the meetings that make the user busy into
+// non-overlapping intervals sorted by start. Declined and transparent
+// meetings don't count.
+func BusyIntervals(events []*MeetingInfo) []Interval {
+	var busy []Interval
+	for _, meeting := range events {
+		if !meeting.IsBusy() || !meeting.End.After(meeting.Start) {
+			continue
+		}
+		busy = append(busy, Interval{Start: meeting.Start, End: meeting.End})
+	}
+
+	sort.Slice(busy, func(i, j int) bool {
+		return busy[i].Start.Before(busy[j].Start)
+	})
+
+	var merged []Interval
+	for _, interval := range busy {
+		last := len(merged) - 1
+		if last >= 0 && !interval.Start.After(merged[last].End) {
+			if interval.End.After(merged[last].End) {
+				merged[last].End = interval.End
+			}
+			continue
+		}
+		merged = append(merged, interval)
+	}
+	return merged
+}
+
+// FreeFor returns how long the user is free from now until the next busy
+// interval starts. It returns false if the user is busy now or has no more
+// meetings, since then there is no block to plan around.
+func FreeFor(events []*MeetingInfo, now time.Time) (time.Duration, bool) {
+	for _, interval := range BusyIntervals(events) {
+		if !now.Before(interval.Start) && now.Before(interval.End) {
+			return 0, false
+		}
+		if now.Before(interval.Start) {
+			return interval.Start.Sub(now), true
+		}
+	}
+	return 0, false
+}
+
+// NextFreeSlot returns the earliest free interval of at least minLength that
+// starts at or after now and ends no later than until. The slot is as long as
+// the free time allows, up to until.
+func NextFreeSlot(events []*MeetingInfo, now time.Time, minLength time.Duration, until time.Time) (Interval, bool) {
+	start := now
+	for _, interval := range BusyIntervals(events) {
+		if !start.Before(until) {
+			break
+		}
+		if !interval.End.After(start) {
+			continue
+		}
+
+		end := interval.Start
+		if end.After(until) {
+			end = until
+		}
+		if end.After(start) && end.Sub(start) >= minLength {
+			return Interval{Start: start, End: end}, true
+		}
+		start = interval.End
+	}
+
+	if start.Before(until) && until.Sub(start) >= minLength {
+		return Interval{Start: start, End: until}, true
+	}
+	return Interval{}, false
+}
@@ -0,0 +1,351 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBusyIntervals(t *testing.T) {
+	base := time.Date(2026, 1, 9, 9, 0, 0, 0, time.UTC)
+
+	makeMeeting := func(startOffset, endOffset time.Duration, status string) *MeetingInfo {
+		return &MeetingInfo{
+			Start:              base.Add(startOffset),
+			End:                base.Add(endOffset),
+			SelfResponseStatus: status,
+		}
+	}
+	interval := func(startOffset, endOffset time.Duration) Interval {
+		return Interval{Start: base.Add(startOffset), End: base.Add(endOffset)}
+	}
+
+	tests := []struct {
+		name   string
+		events []*MeetingInfo
+		want   []Interval
+	}{
+		{
+			name:   "no events",
+			events: nil,
+			want:   nil,
+		},
+		{
+			name:   "single meeting",
+			events: []*MeetingInfo{makeMeeting(0, time.Hour, "accepted")},
+			want:   []Interval{interval(0, time.Hour)},
+		},
+		{
+			name: "separate meetings are sorted",
+			events: []*MeetingInfo{
+				makeMeeting(2*time.Hour, 3*time.Hour, "accepted"),
+				makeMeeting(0, time.Hour, "accepted"),
+			},
+			want: []Interval{interval(0, time.Hour), interval(2*time.Hour, 3*time.Hour)},
+		},
+		{
+			name: "overlapping meetings are merged",
+			events: []*MeetingInfo{
+				makeMeeting(0, time.Hour, "accepted"),
+				makeMeeting(30*time.Minute, 90*time.Minute, "accepted"),
+			},
+			want: []Interval{interval(0, 90*time.Minute)},
+		},
+		{
+			name: "back-to-back meetings are merged",
+			events: []*MeetingInfo{
+				makeMeeting(0, time.Hour, "accepted"),
+				makeMeeting(time.Hour, 2*time.Hour, "accepted"),
+			},
+			want: []Interval{interval(0, 2*time.Hour)},
+		},
+		{
+			name: "contained meeting does not shrink interval",
+			events: []*MeetingInfo{
+				makeMeeting(0, 3*time.Hour, "accepted"),
+				makeMeeting(time.Hour, 2*time.Hour, "accepted"),
+			},
+			want: []Interval{interval(0, 3*time.Hour)},
+		},
+		{
+			name: "chain of overlaps merges into one",
+			events: []*MeetingInfo{
+				makeMeeting(0, time.Hour, "accepted"),
+				makeMeeting(50*time.Minute, 2*time.Hour, "tentative"),
+				makeMeeting(110*time.Minute, 3*time.Hour, "needsAction"),
+			},
+			want: []Interval{interval(0, 3*time.Hour)},
+		},
+		{
+			name: "declined meetings are free time",
+			events: []*MeetingInfo{
+				makeMeeting(0, time.Hour, "declined"),
+				makeMeeting(2*time.Hour, 3*time.Hour, "accepted"),
+			},
+			want: []Interval{interval(2*time.Hour, 3*time.Hour)},
+		},
+		{
+			name: "transparent events are free time",
+			events: []*MeetingInfo{
+				{Start: base, End: base.Add(time.Hour), SelfResponseStatus: "accepted", Transparency: "transparent"},
+				makeMeeting(2*time.Hour, 3*time.Hour, "accepted"),
+			},
+			want: []Interval{interval(2*time.Hour, 3*time.Hour)},
+		},
+		{
+			name: "zero-length meetings are ignored",
+			events: []*MeetingInfo{
+				makeMeeting(time.Hour, time.Hour, "accepted"),
+			},
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BusyIntervals(tt.events)
+			if len(got) != len(tt.want) {
+				t.Fatalf("BusyIntervals() returned %d intervals, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for i := range got {
+				if !got[i].Start.Equal(tt.want[i].Start) || !got[i].End.Equal(tt.want[i].End) {
+					t.Errorf("BusyIntervals()[%d] = %v–%v, want %v–%v",
+						i, got[i].Start, got[i].End, tt.want[i].Start, tt.want[i].End)
+				}
+			}
+		})
+	}
+}
+
+func TestFreeFor(t *testing.T) {
+	fixedNow := time.Date(2026, 1, 9, 14, 30, 0, 0, time.UTC)
+
+	makeMeeting := func(startOffset, endOffset time.Duration, status string) *MeetingInfo {
+		return &MeetingInfo{
+			Start:              fixedNow.Add(startOffset),
+			End:                fixedNow.Add(endOffset),
+			SelfResponseStatus: status,
+		}
+	}
+
+	tests := []struct {
+		name     string
+		events   []*MeetingInfo
+		want     time.Duration
+		wantFree bool
+	}{
+		{
+			name:     "no events",
+			events:   nil,
+			wantFree: false,
+		},
+		{
+			name:     "only past meetings",
+			events:   []*MeetingInfo{makeMeeting(-2*time.Hour, -time.Hour, "accepted")},
+			wantFree: false,
+		},
+		{
+			name:     "in a meeting",
+			events:   []*MeetingInfo{makeMeeting(-10*time.Minute, 20*time.Minute, "accepted")},
+			wantFree: false,
+		},
+		{
+			name:     "meeting starts exactly now",
+			events:   []*MeetingInfo{makeMeeting(0, time.Hour, "accepted")},
+			wantFree: false,
+		},
+		{
+			name:     "meeting ended exactly now",
+			events:   []*MeetingInfo{makeMeeting(-time.Hour, 0, "accepted"), makeMeeting(45*time.Minute, time.Hour, "accepted")},
+			want:     45 * time.Minute,
+			wantFree: true,
+		},
+		{
+			name:     "free until next meeting",
+			events:   []*MeetingInfo{makeMeeting(45*time.Minute, time.Hour, "accepted")},
+			want:     45 * time.Minute,
+			wantFree: true,
+		},
+		{
+			name: "earliest of several upcoming meetings",
+			events: []*MeetingInfo{
+				makeMeeting(2*time.Hour, 3*time.Hour, "accepted"),
+				makeMeeting(30*time.Minute, time.Hour, "accepted"),
+			},
+			want:     30 * time.Minute,
+			wantFree: true,
+		},
+		{
+			name: "declined current meeting is free time",
+			events: []*MeetingInfo{
+				makeMeeting(-10*time.Minute, 20*time.Minute, "declined"),
+				makeMeeting(time.Hour, 2*time.Hour, "accepted"),
+			},
+			want:     time.Hour,
+			wantFree: true,
+		},
+		{
+			name: "declined next meeting is skipped",
+			events: []*MeetingInfo{
+				makeMeeting(15*time.Minute, 30*time.Minute, "declined"),
+				makeMeeting(90*time.Minute, 2*time.Hour, "accepted"),
+			},
+			want:     90 * time.Minute,
+			wantFree: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, free := FreeFor(tt.events, fixedNow)
+			if free != tt.wantFree {
+				t.Fatalf("FreeFor() free = %v, want %v", free, tt.wantFree)
+			}
+			if got != tt.want {
+				t.Errorf("FreeFor() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextFreeSlot(t *testing.T) {
+	fixedNow := time.Date(2026, 1, 9, 14, 0, 0, 0, time.UTC)
+	endOfDay := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	makeMeeting := func(startOffset, endOffset time.Duration) *MeetingInfo {
+		return &MeetingInfo{
+			Start:              fixedNow.Add(startOffset),
+			End:                fixedNow.Add(endOffset),
+			SelfResponseStatus: "accepted",
+		}
+	}
+
+	tests := []struct {
+		name      string
+		events    []*MeetingInfo
+		minLength time.Duration
+		until     time.Time
+		wantStart time.Duration
+		wantEnd   time.Duration
+		wantFound bool
+	}{
+		{
+			name:      "no events - free until end of day",
+			events:    nil,
+			minLength: 30 * time.Minute,
+			until:     endOfDay,
+			wantStart: 0,
+			wantEnd:   10 * time.Hour,
+			wantFound: true,
+		},
+		{
+			name:      "free now and long enough",
+			events:    []*MeetingInfo{makeMeeting(time.Hour, 2*time.Hour)},
+			minLength: 30 * time.Minute,
+			until:     endOfDay,
+			wantStart: 0,
+			wantEnd:   time.Hour,
+			wantFound: true,
+		},
+		{
+			name:      "free now but too short",
+			events:    []*MeetingInfo{makeMeeting(15*time.Minute, 2*time.Hour)},
+			minLength: 30 * time.Minute,
+			until:     endOfDay,
+			wantStart: 2 * time.Hour,
+			wantEnd:   10 * time.Hour,
+			wantFound: true,
+		},
+		{
+			name:      "in a meeting - slot after it",
+			events:    []*MeetingInfo{makeMeeting(-30*time.Minute, 30*time.Minute), makeMeeting(2*time.Hour, 3*time.Hour)},
+			minLength: 30 * time.Minute,
+			until:     endOfDay,
+			wantStart: 30 * time.Minute,
+			wantEnd:   2 * time.Hour,
+			wantFound: true,
+		},
+		{
+			name: "booked solid with short gaps",
+			events: []*MeetingInfo{
+				makeMeeting(-30*time.Minute, time.Hour),
+				makeMeeting(70*time.Minute, 2*time.Hour),
+				makeMeeting(130*time.Minute, 3*time.Hour),
+				makeMeeting(4*time.Hour, 5*time.Hour),
+			},
+			minLength: 45 * time.Minute,
+			until:     endOfDay,
+			wantStart: 3 * time.Hour,
+			wantEnd:   4 * time.Hour,
+			wantFound: true,
+		},
+		{
+			name:      "gap exactly the minimum length",
+			events:    []*MeetingInfo{makeMeeting(-time.Hour, 0), makeMeeting(30*time.Minute, time.Hour)},
+			minLength: 30 * time.Minute,
+			until:     endOfDay,
+			wantStart: 0,
+			wantEnd:   30 * time.Minute,
+			wantFound: true,
+		},
+		{
+			name:      "overlapping meetings leave no gap",
+			events:    []*MeetingInfo{makeMeeting(-time.Hour, time.Hour), makeMeeting(30*time.Minute, 10*time.Hour)},
+			minLength: time.Minute,
+			until:     endOfDay,
+			wantFound: false,
+		},
+		{
+			name:      "slot is cut at until",
+			events:    []*MeetingInfo{makeMeeting(-time.Hour, time.Hour)},
+			minLength: 30 * time.Minute,
+			until:     fixedNow.Add(2 * time.Hour),
+			wantStart: time.Hour,
+			wantEnd:   2 * time.Hour,
+			wantFound: true,
+		},
+		{
+			name:      "meeting after until does not matter",
+			events:    []*MeetingInfo{makeMeeting(-time.Hour, time.Hour), makeMeeting(3*time.Hour, 4*time.Hour)},
+			minLength: 30 * time.Minute,
+			until:     fixedNow.Add(2 * time.Hour),
+			wantStart: time.Hour,
+			wantEnd:   2 * time.Hour,
+			wantFound: true,
+		},
+		{
+			name:      "remaining time before until too short",
+			events:    []*MeetingInfo{makeMeeting(-time.Hour, 100*time.Minute)},
+			minLength: 30 * time.Minute,
+			until:     fixedNow.Add(2 * time.Hour),
+			wantFound: false,
+		},
+		{
+			name:      "past meetings are ignored",
+			events:    []*MeetingInfo{makeMeeting(-3*time.Hour, -2*time.Hour), makeMeeting(time.Hour, 2*time.Hour)},
+			minLength: 30 * time.Minute,
+			until:     endOfDay,
+			wantStart: 0,
+			wantEnd:   time.Hour,
+			wantFound: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := NextFreeSlot(tt.events, fixedNow, tt.minLength, tt.until)
+			if found != tt.wantFound {
+				t.Fatalf("NextFreeSlot() found = %v, want %v (got %v–%v)", found, tt.wantFound, got.Start, got.End)
+			}
+			if !found {
+				return
+			}
+			wantStart, wantEnd := fixedNow.Add(tt.wantStart), fixedNow.Add(tt.wantEnd)
+			if !got.Start.Equal(wantStart) || !got.End.Equal(wantEnd) {
+				t.Errorf("NextFreeSlot() = %v–%v, want %v–%v", got.Start, got.End, wantStart, wantEnd)
+			}
+			if got.Duration() != wantEnd.Sub(wantStart) {
+				t.Errorf("Duration() = %v, want %v", got.Duration(), wantEnd.Sub(wantStart))
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package clock
+
+import (
+	"fmt"
+	"time"
+)
+
+// Clock tells the current time
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// System returns a clock that reads the system time
+func System() Clock {
+	return systemClock{}
+}
+
+type fixedClock struct {
+	t time.Time
+}
+
+func (c fixedClock) Now() time.Time {
+	return c.t
+}
+
+// Fixed returns a clock that is stopped at t
+func Fixed(t time.Time) Clock {
+	return fixedClock{t: t}
+}
+
+type offsetClock struct {
+	offset time.Duration
+}
+
+func (c offsetClock) Now() time.Time {
+	return time.Now().Add(c.offset)
+}
+
+// StartingAt returns a clock that reports t now and keeps running from there
+func StartingAt(t time.Time) Clock {
+	return offsetClock{offset: time.Until(t)}
+}
+
+// Parse parses a time override given on the command line.
+// Accepts RFC 3339, "2006-01-02 15:04[:05]" in local time, or "15:04" for today.
+func Parse(value string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	for _, layout := range []string{time.DateTime, "2006-01-02 15:04"} {
+		if t, err := time.ParseInLocation(layout, value, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+
+	if t, err := time.ParseInLocation("15:04", value, now.Location()); err == nil {
+		year, month, day := now.Date()
+		return time.Date(year, month, day, t.Hour(), t.Minute(), 0, 0, now.Location()), nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time %q (use RFC 3339, \"2006-01-02 15:04\" or \"15:04\")", value)
+}
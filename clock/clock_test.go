@@ -0,0 +1,81 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	loc := time.FixedZone("CET", 3600)
+	now := time.Date(2026, 1, 9, 10, 0, 0, 0, loc)
+
+	tests := []struct {
+		name    string
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{
+			name:  "RFC 3339",
+			value: "2026-01-09T13:59:00Z",
+			want:  time.Date(2026, 1, 9, 13, 59, 0, 0, time.UTC),
+		},
+		{
+			name:  "date and time in local zone",
+			value: "2026-01-09 13:59",
+			want:  time.Date(2026, 1, 9, 13, 59, 0, 0, loc),
+		},
+		{
+			name:  "date and time with seconds",
+			value: "2026-01-09 13:59:30",
+			want:  time.Date(2026, 1, 9, 13, 59, 30, 0, loc),
+		},
+		{
+			name:  "time of day is today",
+			value: "13:59",
+			want:  time.Date(2026, 1, 9, 13, 59, 0, 0, loc),
+		},
+		{
+			name:    "invalid value",
+			value:   "friday afternoon",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.value, now)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Parse(%q) expected error, got %v", tt.value, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q) failed: %v", tt.value, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("Parse(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStartingAt(t *testing.T) {
+	start := time.Date(2026, 1, 9, 13, 59, 0, 0, time.UTC)
+	c := StartingAt(start)
+
+	got := c.Now()
+	if got.Before(start) || got.Sub(start) > time.Second {
+		t.Errorf("expected clock to start at %v, got %v", start, got)
+	}
+}
+
+func TestFixed(t *testing.T) {
+	start := time.Date(2026, 1, 9, 13, 59, 0, 0, time.UTC)
+	c := Fixed(start)
+
+	if !c.Now().Equal(start) || !c.Now().Equal(start) {
+		t.Errorf("expected fixed clock to always report %v", start)
+	}
+}
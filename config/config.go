@@ -0,0 +1,154 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	appName        = "next-meeting"
+	configFileName = "config.json"
+
+	// DefaultCacheTTL is how long fetched events are cached unless configured otherwise
+	DefaultCacheTTL = 30 * time.Minute
+	// DefaultMinBreak is the shortest gap between meetings that counts as a break
+	DefaultMinBreak = 10 * time.Minute
+	// DefaultHookTimeout is how long a hook may run unless configured otherwise
+	DefaultHookTimeout = 10 * time.Second
+	// DefaultSoonThreshold is how close a meeting must be to count as starting soon
+	DefaultSoonThreshold = 10 * time.Minute
+)
+
+// Duration is a time.Duration that is read from JSON as a string like "30m"
+type Duration time.Duration
+
+// UnmarshalJSON parses a duration string such as "5m" or "1h30m"
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"30m\": %w", err)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// MarshalJSON writes the duration as a string like "30m0s"
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// Config holds the user settings stored in config.json
+type Config struct {
+	CacheTTL     Duration `json:"cache_ttl"`
+	EncryptCache bool     `json:"encrypt_cache"` // Encrypt cached events and notification markers at rest
+
+	// Output templates (Go text/template). Format applies to every state unless
+	// a state-specific template is set.
+	Format          string `json:"format"`
+	FormatInMeeting string `json:"format_in_meeting"`
+	FormatUpcoming  string `json:"format_upcoming"`
+	FormatEmpty     string `json:"format_empty"`
+
+	ShowFree bool     `json:"show_free"` // Add the free time segment to the default status line
+	FreeSlot Duration `json:"free_slot"` // When booked solid, show the next free slot of at least this length
+
+	NotifyConflicts bool `json:"notify_conflicts"` // Notify once a day when meetings overlap
+
+	BackToBackLimit Duration `json:"back_to_back_limit"` // Warn about back-to-back meetings longer than this
+	MinBreak        Duration `json:"min_break"`          // Gaps shorter than this don't count as a break
+
+	HTTPAddr  string `json:"http_addr"`  // Address the daemon serves the HTTP API on; empty disables it
+	HTTPToken string `json:"http_token"` // Token HTTP clients must send, required unless bound to loopback
+
+	DBus bool `json:"dbus"` // Publish the status on the D-Bus session bus while the daemon runs
+
+	Hooks       map[string]string `json:"hooks"`        // Command to run for each status transition, e.g. "meeting-started"
+	HookTimeout Duration          `json:"hook_timeout"` // How long a hook may run before it is killed
+
+	Webhooks []Webhook `json:"webhooks"` // URLs transitions are posted to
+
+	Slack            bool   `json:"slack"`              // Set the Slack status while in a meeting
+	SlackStatusText  string `json:"slack_status_text"`  // Go text/template for the status text, with the meeting as data
+	SlackStatusEmoji string `json:"slack_status_emoji"` // Emoji shown with the status, e.g. ":calendar:"
+	SlackBaseURL     string `json:"slack_base_url"`     // Slack Web API URL, for testing or proxies
+
+	MQTTBroker          string `json:"mqtt_broker"`           // Broker the daemon publishes the status to, e.g. tcp://localhost:1883; empty disables it
+	MQTTUsername        string `json:"mqtt_username"`         // Username for the broker; the password is kept in the keyring
+	MQTTClientID        string `json:"mqtt_client_id"`        // Client ID, also used to identify Home Assistant entities
+	MQTTTopic           string `json:"mqtt_topic"`            // Topic prefix the status is published under
+	MQTTCAFile          string `json:"mqtt_ca_file"`          // CA certificate to verify an ssl:// broker with instead of the system roots
+	MQTTDiscovery       bool   `json:"mqtt_discovery"`        // Publish Home Assistant discovery config
+	MQTTDiscoveryPrefix string `json:"mqtt_discovery_prefix"` // Topic prefix Home Assistant watches for discovery
+
+	SoonThreshold Duration          `json:"soon_threshold"` // A meeting starting within this is "soon" for status bars
+	Colors        map[string]string `json:"colors"`         // Color per state for polybar and tmux output
+}
+
+// Webhook is a URL status transitions are posted to
+type Webhook struct {
+	URL      string   `json:"url"`
+	Secret   string   `json:"secret"`   // Signs the body with HMAC-SHA256 if set
+	Events   []string `json:"events"`   // Transitions to post, e.g. "meeting-started"; all if empty
+	Template string   `json:"template"` // Go text/template for the body instead of the default JSON payload
+}
+
+// DefaultColors are the colors used for each state unless configured otherwise
+var DefaultColors = map[string]string{
+	"in-meeting": "#f38ba8",
+	"soon":       "#fab387",
+	"free":       "#a6e3a1",
+	"offline":    "#6c7086",
+	"logged-out": "#6c7086",
+}
+
+// Default returns the configuration used when no config file exists
+func Default() *Config {
+	return &Config{
+		CacheTTL:      Duration(DefaultCacheTTL),
+		MinBreak:      Duration(DefaultMinBreak),
+		HookTimeout:   Duration(DefaultHookTimeout),
+		SoonThreshold: Duration(DefaultSoonThreshold),
+		Colors:        maps.Clone(DefaultColors),
+	}
+}
+
+// GetPath returns the path where config.json should be stored
+func GetPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user config directory: %w", err)
+	}
+	return filepath.Join(configDir, appName, configFileName), nil
+}
+
+// Load reads the config file, falling back to defaults for anything not set.
+// A missing config file is not an error.
+func Load() (*Config, error) {
+	cfg := Default()
+
+	path, err := GetPath()
+	if err != nil {
+		return cfg, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return cfg, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return Default(), fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"next-meeting/calendar"
+	"next-meeting/clock"
+	"next-meeting/config"
+	"next-meeting/daemon"
+	"next-meeting/dbusapi"
+	"next-meeting/httpapi"
+	"next-meeting/monitor"
+	"next-meeting/mqttpub"
+	"next-meeting/notify"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// daemonQueryTimeout is how long the CLI waits for the daemon to answer.
+// Refreshing may hit the calendar API, so it gets longer.
+const (
+	daemonQueryTimeout   = 2 * time.Second
+	daemonRefreshTimeout = 30 * time.Second
+)
+
+// warningInterval is how often the daemon checks whether to warn about
+// back-to-back meetings and double-bookings
+const warningInterval = 15 * time.Second
+
+// runDaemon keeps today's events in memory, notifies at each of the thresholds
+// before meetings start, warns about back-to-back meetings and double-bookings
+// and answers clients on the daemon socket, and on HTTP, D-Bus and MQTT if
+// configured, until ctx is cancelled
+func runDaemon(ctx context.Context, cfg *config.Config, mon *monitor.Monitor, clk clock.Clock, thresholds notify.Thresholds) error {
+	if cfg.HTTPAddr != "" {
+		if err := httpapi.CheckAddr(cfg.HTTPAddr, cfg.HTTPToken); err != nil {
+			return err
+		}
+	}
+
+	var publisher *mqttpub.Publisher
+	if cfg.MQTTBroker != "" {
+		opts, err := mqttOptions(cfg)
+		if err != nil {
+			return err
+		}
+		publisher = mqttpub.NewPublisher(mon, opts)
+	}
+
+	l, err := daemon.Listen(daemon.SocketPath())
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	if err := mon.Refresh(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to load events: %v\n", err)
+	}
+	go mon.Run(ctx)
+
+	if tracker := newTracker(cfg); tracker != nil {
+		go watchTransitions(ctx, cfg, tracker, mon)
+	}
+
+	if !replay && (len(thresholds.Fixed) > 0 || thresholds.Reminders) {
+		scheduler := daemon.NewScheduler(clk, thresholds.For, func(meetings []*calendar.MeetingInfo, startsIn, threshold time.Duration) {
+			var alerts []notify.Alert
+			for _, meeting := range meetings {
+				forMeeting := thresholds.For(meeting)
+				alerts = append(alerts, notify.Alert{Meeting: meeting, Threshold: threshold, Last: threshold == forMeeting[len(forMeeting)-1]})
+			}
+			sendMeetingNotification(alerts, startsIn)
+		})
+		defer scheduler.Stop()
+
+		updates, unsubscribe := mon.Subscribe()
+		defer unsubscribe()
+		scheduler.Schedule(mon.Events())
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-updates:
+					notify.CleanOldNotifications(clk.Now())
+					scheduler.Schedule(mon.Events())
+				}
+			}
+		}()
+	}
+
+	chainWarnings := cfg.BackToBackLimit > 0 && (len(thresholds.Fixed) > 0 || thresholds.Reminders)
+	if !replay && (chainWarnings || cfg.NotifyConflicts) {
+		go watchWarnings(ctx, cfg, mon, thresholds, chainWarnings)
+	}
+
+	if cfg.HTTPAddr != "" {
+		srv := httpapi.NewServer(mon, httpapi.Options{
+			Token:     cfg.HTTPToken,
+			Soon:      time.Duration(cfg.SoonThreshold),
+			IsOffline: isNetworkError,
+		})
+		go func() {
+			if err := srv.Serve(ctx, cfg.HTTPAddr); err != nil {
+				cancel(fmt.Errorf("HTTP server: %w", err))
+			}
+		}()
+	}
+
+	if cfg.DBus {
+		conn, err := dbus.ConnectSessionBus()
+		if err != nil {
+			l.Close()
+			return fmt.Errorf("connecting to the session bus: %w", err)
+		}
+		defer conn.Close()
+
+		svc := dbusapi.NewService(mon, dbusapi.Options{
+			Soon:      time.Duration(cfg.SoonThreshold),
+			IsOffline: isNetworkError,
+			Join:      joinMeeting,
+		})
+		go func() {
+			if err := svc.Serve(ctx, conn); err != nil {
+				cancel(fmt.Errorf("D-Bus service: %w", err))
+			}
+		}()
+	}
+
+	if publisher != nil {
+		go func() {
+			// Only MQTT stops if the publisher fails; the rest keeps serving
+			if err := publisher.Serve(ctx); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: MQTT publisher: %v\n", err)
+			}
+		}()
+	}
+
+	if err := daemon.NewServer(mon, isNetworkError).Serve(ctx, l); err != nil {
+		return err
+	}
+	if err := context.Cause(ctx); !errors.Is(err, context.Canceled) {
+		return err
+	}
+	return nil
+}
+
+// watchWarnings warns about back-to-back meetings and double-bookings in the
+// events kept by mon until ctx is cancelled
+func watchWarnings(ctx context.Context, cfg *config.Config, mon *monitor.Monitor, thresholds notify.Thresholds, chainWarnings bool) {
+	ticker := time.NewTicker(warningInterval)
+	defer ticker.Stop()
+
+	for {
+		// Nothing is known about the day until events were loaded once
+		if !mon.FetchedAt().IsZero() {
+			now := mon.Now()
+			events := mon.Events()
+			if chainWarnings {
+				sendChainWarning(cfg, events, calendar.GetMeetingStatus(events, now), thresholds, now)
+			}
+			if cfg.NotifyConflicts {
+				sendConflictWarning(events, now)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sendMeetingNotification announces meetings starting at the same time with
+// one notification, sending each alert once however many processes try
+func sendMeetingNotification(alerts []notify.Alert, startsIn time.Duration) {
+	defer lockNotifications()()
+
+	alerts = slices.DeleteFunc(slices.Clone(alerts), func(alert notify.Alert) bool {
+		return notify.HasBeenNotified(alert.Meeting, alert.Threshold)
+	})
+	if len(alerts) == 0 {
+		return
+	}
+	if err := notify.SendGroupNotification(alerts, startsIn); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to send notification: %v\n", err)
+		return
+	}
+	for _, alert := range alerts {
+		if err := notify.MarkNotified(alert.Meeting, alert.Threshold); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to mark notification: %v\n", err)
+		}
+	}
+}
+
+// lockNotifications keeps other processes from sending the same notification
+// until the returned function is called. If locking fails, notifications are
+// sent anyway rather than missed.
+func lockNotifications() (unlock func()) {
+	unlock, err := notify.Lock()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to lock notifications: %v\n", err)
+		return func() {}
+	}
+	return unlock
+}
+
+// queryDaemon asks a running daemon for today's events. It returns false if
+// no daemon is running, in which case the caller loads events itself.
+func queryDaemon(refresh bool) (*daemon.Response, bool) {
+	command, timeout := daemon.CommandStatus, daemonQueryTimeout
+	if refresh {
+		command, timeout = daemon.CommandRefresh, daemonRefreshTimeout
+	}
+
+	resp, err := daemon.Query(daemon.SocketPath(), command, timeout)
+	if err != nil {
+		return nil, false
+	}
+	return resp, true
+}
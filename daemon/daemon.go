@@ -0,0 +1,179 @@
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"next-meeting/calendar"
+	"next-meeting/monitor"
+	"next-meeting/output"
+)
+
+const socketName = "next-meeting.sock"
+
+// Commands understood by the daemon
+const (
+	CommandStatus  = "status"  // Return the events in memory and the current status
+	CommandRefresh = "refresh" // Fetch events now, then answer like CommandStatus
+)
+
+// dialTimeout is how long clients wait for the daemon to accept a connection
+const dialTimeout = 200 * time.Millisecond
+
+// Request is sent by clients as a single JSON line
+type Request struct {
+	Command string `json:"command"`
+}
+
+// Response is the daemon's answer, a single JSON line
+type Response struct {
+	Events    []*calendar.MeetingInfo `json:"events"`
+	FetchedAt time.Time               `json:"fetched_at"`
+	Status    *output.Status          `json:"status"`
+	Error     string                  `json:"error,omitempty"`
+	Offline   bool                    `json:"offline,omitempty"` // The last refresh failed because the calendar could not be reached
+}
+
+// SocketPath returns where the daemon listens: in $XDG_RUNTIME_DIR, or in a
+// private directory under the temp dir when that is not set
+func SocketPath() string {
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), fmt.Sprintf("next-meeting-%d", os.Getuid()))
+	}
+	return filepath.Join(dir, socketName)
+}
+
+// Server answers status requests from the events kept by a monitor
+type Server struct {
+	mon       *monitor.Monitor
+	isOffline func(error) bool
+}
+
+// NewServer creates a server for mon. isOffline tells whether a refresh error
+// means the calendar could not be reached.
+func NewServer(mon *monitor.Monitor, isOffline func(error) bool) *Server {
+	return &Server{mon: mon, isOffline: isOffline}
+}
+
+// Listen creates the Unix socket at path, replacing a stale one left behind
+// by a daemon that is no longer running
+func Listen(path string) (net.Listener, error) {
+	if Running(path) {
+		return nil, errors.New("daemon is already running")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+	_ = os.Remove(path)
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// Serve answers requests on l until ctx is cancelled, then closes l
+func (s *Server) Serve(ctx context.Context, l net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		l.Close()
+	}()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		go s.handle(ctx, conn)
+	}
+}
+
+// handle answers a single request
+func (s *Server) handle(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+
+	line, err := bufio.NewReader(conn).ReadBytes('\n')
+	if err != nil && len(line) == 0 {
+		return
+	}
+
+	var req Request
+	if err := json.Unmarshal(line, &req); err != nil {
+		_ = json.NewEncoder(conn).Encode(&Response{Error: fmt.Sprintf("invalid request: %v", err)})
+		return
+	}
+
+	_ = json.NewEncoder(conn).Encode(s.respond(ctx, req))
+}
+
+// respond builds the answer to req
+func (s *Server) respond(ctx context.Context, req Request) *Response {
+	switch req.Command {
+	case CommandStatus:
+	case CommandRefresh:
+		_ = s.mon.ForceRefresh(ctx)
+	default:
+		return &Response{Error: fmt.Sprintf("unknown command %q", req.Command)}
+	}
+
+	now := s.mon.Now()
+	resp := &Response{
+		Events:    s.mon.Events(),
+		FetchedAt: s.mon.FetchedAt(),
+	}
+	if err := s.mon.Err(); err != nil {
+		resp.Error = err.Error()
+		resp.Offline = s.isOffline(err)
+	}
+	if resp.Events != nil || resp.Error == "" {
+		resp.Status = output.NewStatus(calendar.GetMeetingStatus(resp.Events, now), now, now.Sub(resp.FetchedAt))
+	}
+	return resp
+}
+
+// Running reports whether a daemon answers on path
+func Running(path string) bool {
+	conn, err := net.DialTimeout("unix", path, dialTimeout)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// Query sends command to the daemon listening on path and waits at most
+// timeout for the answer. It fails quickly when no daemon is running.
+func Query(path, command string, timeout time.Duration) (*Response, error) {
+	conn, err := net.DialTimeout("unix", path, dialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+
+	if err := json.NewEncoder(conn).Encode(&Request{Command: command}); err != nil {
+		return nil, err
+	}
+
+	var resp Response
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("invalid response from daemon: %w", err)
+	}
+	return &resp, nil
+}
@@ -0,0 +1,367 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"next-meeting/calendar"
+	"next-meeting/clock"
+	"next-meeting/monitor"
+)
+
+// startServer serves mon on a socket in a temp dir until the test ends
+func startServer(t *testing.T, mon *monitor.Monitor) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), socketName)
+
+	l, err := Listen(path)
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		_ = NewServer(mon, func(err error) bool { return err.Error() == "offline" }).Serve(ctx, l)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return path
+}
+
+func TestSocketPath(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", "/run/user/1000")
+	if got := SocketPath(); got != "/run/user/1000/next-meeting.sock" {
+		t.Errorf("SocketPath() = %q", got)
+	}
+
+	t.Setenv("XDG_RUNTIME_DIR", "")
+	t.Setenv("TMPDIR", "/tmp/test")
+	if got := SocketPath(); filepath.Dir(filepath.Dir(got)) != "/tmp/test" {
+		t.Errorf("SocketPath() = %q, want a path under the temp dir", got)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	fixedNow := time.Date(2026, 1, 9, 14, 30, 0, 0, time.UTC)
+	events := []*calendar.MeetingInfo{
+		{Summary: "Current", Start: fixedNow.Add(-time.Minute), End: fixedNow.Add(time.Hour)},
+		{Summary: "Next", Start: fixedNow.Add(2 * time.Hour), End: fixedNow.Add(3 * time.Hour)},
+	}
+
+	var mu sync.Mutex
+	var fetchErr error
+	calls, forced := 0, 0
+	fetch := func(ctx context.Context, force bool) ([]*calendar.MeetingInfo, time.Time, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		if force {
+			forced++
+		}
+		return events, fixedNow, fetchErr
+	}
+	mon := monitor.New(fetch, clock.Fixed(fixedNow), time.Minute)
+	if err := mon.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+
+	path := startServer(t, mon)
+
+	if !Running(path) {
+		t.Fatalf("expected daemon to be running")
+	}
+
+	resp, err := Query(path, CommandStatus, time.Second)
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(resp.Events) != 2 || resp.Events[1].Summary != "Next" {
+		t.Errorf("unexpected events: %+v", resp.Events)
+	}
+	if !resp.FetchedAt.Equal(fixedNow) {
+		t.Errorf("FetchedAt = %v, want %v", resp.FetchedAt, fixedNow)
+	}
+	if resp.Status == nil || resp.Status.Current == nil || resp.Status.Current.Summary != "Current" {
+		t.Errorf("unexpected status: %+v", resp.Status)
+	}
+	if resp.Error != "" || resp.Offline {
+		t.Errorf("unexpected error: %q (offline %v)", resp.Error, resp.Offline)
+	}
+
+	t.Run("refresh bypasses the cache", func(t *testing.T) {
+		if _, err := Query(path, CommandRefresh, time.Second); err != nil {
+			t.Fatalf("Query failed: %v", err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if calls != 2 || forced != 1 {
+			t.Errorf("expected 2 fetches with 1 forced, got %d with %d forced", calls, forced)
+		}
+	})
+
+	t.Run("offline keeps events", func(t *testing.T) {
+		mu.Lock()
+		fetchErr = errors.New("offline")
+		mu.Unlock()
+
+		resp, err := Query(path, CommandRefresh, time.Second)
+		if err != nil {
+			t.Fatalf("Query failed: %v", err)
+		}
+		if !resp.Offline || resp.Error != "offline" {
+			t.Errorf("expected offline error, got %q (offline %v)", resp.Error, resp.Offline)
+		}
+		if len(resp.Events) != 2 {
+			t.Errorf("expected previous events to be kept, got %d", len(resp.Events))
+		}
+	})
+
+	t.Run("unknown command", func(t *testing.T) {
+		resp, err := Query(path, "launch", time.Second)
+		if err != nil {
+			t.Fatalf("Query failed: %v", err)
+		}
+		if resp.Error == "" {
+			t.Errorf("expected an error for an unknown command")
+		}
+	})
+}
+
+func TestQueryWithoutDaemon(t *testing.T) {
+	path := filepath.Join(t.TempDir(), socketName)
+	if Running(path) {
+		t.Fatalf("expected no daemon to be running")
+	}
+	if _, err := Query(path, CommandStatus, time.Second); err == nil {
+		t.Fatalf("expected Query to fail without a daemon")
+	}
+}
+
+func TestListenRefusesSecondDaemon(t *testing.T) {
+	fetch := func(ctx context.Context, force bool) ([]*calendar.MeetingInfo, time.Time, error) {
+		return nil, time.Now(), nil
+	}
+	path := startServer(t, monitor.New(fetch, clock.System(), time.Minute))
+
+	if _, err := Listen(path); err == nil {
+		t.Fatalf("expected Listen to fail while a daemon is running")
+	}
+}
+
+// fixed returns the same thresholds for every meeting
+func fixed(thresholds ...time.Duration) ThresholdsFunc {
+	return func(*calendar.MeetingInfo) []time.Duration {
+		return thresholds
+	}
+}
+
+func TestScheduler(t *testing.T) {
+	now := time.Now()
+	soon := &calendar.MeetingInfo{Summary: "Soon", Start: now.Add(5*time.Minute + 20*time.Millisecond), End: now.Add(time.Hour)}
+	within := &calendar.MeetingInfo{Summary: "Within", Start: now.Add(2 * time.Minute), End: now.Add(time.Hour)}
+	later := &calendar.MeetingInfo{Summary: "Later", Start: now.Add(2 * time.Hour), End: now.Add(3 * time.Hour)}
+	started := &calendar.MeetingInfo{Summary: "Started", Start: now.Add(-time.Minute), End: now.Add(time.Hour)}
+
+	fired := make(chan string, 10)
+	s := NewScheduler(clock.System(), fixed(5*time.Minute), func(meetings []*calendar.MeetingInfo, startsIn, threshold time.Duration) {
+		for _, meeting := range meetings {
+			fired <- meeting.Summary
+		}
+	})
+	defer s.Stop()
+
+	s.Schedule([]*calendar.MeetingInfo{later, soon, started, within})
+
+	got := map[string]bool{}
+	timeout := time.After(2 * time.Second)
+	for len(got) < 2 {
+		select {
+		case summary := <-fired:
+			got[summary] = true
+		case <-timeout:
+			t.Fatalf("expected Soon and Within to fire, got %v", got)
+		}
+	}
+	if !got["Soon"] || !got["Within"] {
+		t.Errorf("expected Soon and Within to fire, got %v", got)
+	}
+
+	select {
+	case summary := <-fired:
+		t.Errorf("unexpected notification for %s", summary)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestSchedulerThresholds(t *testing.T) {
+	now := time.Now()
+	meeting := &calendar.MeetingInfo{Summary: "Review", Start: now.Add(time.Minute + 30*time.Millisecond), End: now.Add(time.Hour)}
+	late := &calendar.MeetingInfo{Summary: "Late", Start: now.Add(30 * time.Second), End: now.Add(time.Hour)}
+
+	fired := make(chan string, 10)
+	s := NewScheduler(clock.System(), fixed(10*time.Minute, 5*time.Minute, time.Minute), func(meetings []*calendar.MeetingInfo, startsIn, threshold time.Duration) {
+		for _, meeting := range meetings {
+			fired <- meeting.Summary + " " + threshold.String()
+		}
+	})
+	defer s.Stop()
+
+	s.Schedule([]*calendar.MeetingInfo{meeting, late})
+
+	// Review is within 5m right away and within 1m shortly after; Late is
+	// already within 1m, so its larger thresholds are skipped
+	want := map[string]bool{"Review 5m0s": true, "Review 1m0s": true, "Late 1m0s": true}
+	got := map[string]bool{}
+	timeout := time.After(2 * time.Second)
+	for len(got) < len(want) {
+		select {
+		case fired := <-fired:
+			if !want[fired] || got[fired] {
+				t.Errorf("unexpected notification %s", fired)
+			}
+			got[fired] = true
+		case <-timeout:
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+
+	select {
+	case fired := <-fired:
+		t.Errorf("unexpected notification %s", fired)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestSchedulerPerMeetingThresholds(t *testing.T) {
+	now := time.Now()
+	reminded := &calendar.MeetingInfo{Summary: "Reminded", Start: now.Add(30*time.Minute + 20*time.Millisecond), End: now.Add(time.Hour), Reminders: []time.Duration{30 * time.Minute}}
+	plain := &calendar.MeetingInfo{Summary: "Plain", Start: now.Add(30*time.Minute + 20*time.Millisecond), End: now.Add(time.Hour)}
+
+	fired := make(chan string, 10)
+	reminders := func(meeting *calendar.MeetingInfo) []time.Duration { return meeting.Reminders }
+	s := NewScheduler(clock.System(), reminders, func(meetings []*calendar.MeetingInfo, startsIn, threshold time.Duration) {
+		for _, meeting := range meetings {
+			fired <- meeting.Summary
+		}
+	})
+	defer s.Stop()
+
+	s.Schedule([]*calendar.MeetingInfo{plain, reminded})
+
+	select {
+	case summary := <-fired:
+		if summary != "Reminded" {
+			t.Errorf("unexpected notification for %s", summary)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the reminder to fire")
+	}
+	select {
+	case summary := <-fired:
+		t.Errorf("unexpected notification for %s", summary)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestSchedulerReminderAtStart(t *testing.T) {
+	now := time.Now()
+	atStart := &calendar.MeetingInfo{Summary: "At start", Start: now.Add(20 * time.Millisecond), End: now.Add(time.Hour), Reminders: []time.Duration{0}}
+	started := &calendar.MeetingInfo{Summary: "Started", Start: now.Add(-time.Minute), End: now.Add(time.Hour), Reminders: []time.Duration{0}}
+	plain := &calendar.MeetingInfo{Summary: "Plain", Start: now.Add(-time.Minute), End: now.Add(time.Hour)}
+
+	fired := make(chan string, 10)
+	reminders := func(meeting *calendar.MeetingInfo) []time.Duration { return meeting.Reminders }
+	s := NewScheduler(clock.System(), reminders, func(meetings []*calendar.MeetingInfo, startsIn, threshold time.Duration) {
+		for _, meeting := range meetings {
+			fired <- meeting.Summary
+		}
+	})
+	defer s.Stop()
+
+	// A meeting that just started still gets its reminder at the start time
+	s.Schedule([]*calendar.MeetingInfo{plain, started, atStart})
+
+	var got []string
+	for range 2 {
+		select {
+		case summary := <-fired:
+			got = append(got, summary)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("expected reminders at the start, got %v", got)
+		}
+	}
+	if strings.Join(got, ",") != "Started,At start" {
+		t.Errorf("unexpected notifications %v", got)
+	}
+	select {
+	case summary := <-fired:
+		t.Errorf("unexpected notification for %s", summary)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestSchedulerSimultaneousStarts(t *testing.T) {
+	now := time.Now()
+	start := now.Add(5*time.Minute + 20*time.Millisecond)
+	planning := &calendar.MeetingInfo{Summary: "Planning", Start: start, End: now.Add(time.Hour)}
+	allHands := &calendar.MeetingInfo{Summary: "All hands", Start: start, End: now.Add(30 * time.Minute)}
+	review := &calendar.MeetingInfo{Summary: "Review", Start: start.Add(time.Minute), End: now.Add(time.Hour)}
+
+	fired := make(chan []string, 10)
+	s := NewScheduler(clock.System(), fixed(5*time.Minute), func(meetings []*calendar.MeetingInfo, startsIn, threshold time.Duration) {
+		var summaries []string
+		for _, meeting := range meetings {
+			summaries = append(summaries, meeting.Summary)
+		}
+		fired <- summaries
+	})
+	defer s.Stop()
+
+	s.Schedule([]*calendar.MeetingInfo{planning, allHands, review})
+
+	select {
+	case summaries := <-fired:
+		if strings.Join(summaries, ",") != "Planning,All hands" {
+			t.Errorf("expected Planning and All hands together, got %v", summaries)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the simultaneous meetings to fire")
+	}
+	select {
+	case summaries := <-fired:
+		t.Errorf("unexpected notification for %v", summaries)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestSchedulerReschedule(t *testing.T) {
+	now := time.Now()
+	meeting := &calendar.MeetingInfo{Summary: "Moved", Start: now.Add(5*time.Minute + 30*time.Millisecond), End: now.Add(time.Hour)}
+
+	fired := make(chan string, 10)
+	s := NewScheduler(clock.System(), fixed(5*time.Minute), func(meetings []*calendar.MeetingInfo, startsIn, threshold time.Duration) {
+		for _, meeting := range meetings {
+			fired <- meeting.Summary
+		}
+	})
+	defer s.Stop()
+
+	s.Schedule([]*calendar.MeetingInfo{meeting})
+	// The meeting was cancelled before its notification was due
+	s.Schedule(nil)
+
+	select {
+	case summary := <-fired:
+		t.Errorf("unexpected notification for %s after rescheduling", summary)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,95 @@
+package daemon
+
+import (
+	"sync"
+	"time"
+
+	"next-meeting/calendar"
+	"next-meeting/clock"
+	"next-meeting/notify"
+)
+
+// NotifyFunc is called when meetings come within one of their notification
+// thresholds. Meetings starting at the same time are passed together.
+type NotifyFunc func(meetings []*calendar.MeetingInfo, startsIn, threshold time.Duration)
+
+// ThresholdsFunc returns how long before meeting to notify, largest first
+type ThresholdsFunc func(meeting *calendar.MeetingInfo) []time.Duration
+
+// Scheduler fires a notification for each meeting exactly when it comes within
+// each threshold, instead of waiting for the next poll
+type Scheduler struct {
+	clock      clock.Clock
+	thresholds ThresholdsFunc
+	notify     NotifyFunc
+
+	mu     sync.Mutex
+	timers []*time.Timer
+}
+
+// NewScheduler creates a scheduler that calls notify at each of the thresholds
+// of a meeting before it starts
+func NewScheduler(clk clock.Clock, thresholds ThresholdsFunc, notify NotifyFunc) *Scheduler {
+	return &Scheduler{clock: clk, thresholds: thresholds, notify: notify}
+}
+
+// Schedule replaces the pending notifications with one per threshold for each
+// meeting that has not started yet, or just started for a reminder at its
+// start time. Meetings already within thresholds fire right away, for the
+// smallest of them only. Meetings starting at the same time share a
+// notification.
+func (s *Scheduler) Schedule(events []*calendar.MeetingInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stop()
+
+	type due struct {
+		start     time.Time
+		threshold time.Duration
+	}
+	var order []due
+	meetings := map[due][]*calendar.MeetingInfo{}
+
+	now := s.clock.Now()
+	for _, meeting := range events {
+		startsIn := meeting.Start.Sub(now)
+		thresholds := s.thresholds(meeting)
+		if len(thresholds) == 0 || !notify.StillDue(startsIn, thresholds[len(thresholds)-1]) {
+			continue
+		}
+		for i, threshold := range thresholds {
+			if i+1 < len(thresholds) && startsIn <= thresholds[i+1] {
+				continue
+			}
+			key := due{start: meeting.Start.UTC(), threshold: threshold}
+			if meetings[key] == nil {
+				order = append(order, key)
+			}
+			meetings[key] = append(meetings[key], meeting)
+		}
+	}
+
+	for _, key := range order {
+		meetings := meetings[key]
+		delay := max(key.start.Sub(now)-key.threshold, 0)
+		s.timers = append(s.timers, time.AfterFunc(delay, func() {
+			if startsIn := key.start.Sub(s.clock.Now()); notify.StillDue(startsIn, key.threshold) {
+				s.notify(meetings, startsIn, key.threshold)
+			}
+		}))
+	}
+}
+
+// Stop cancels all pending notifications
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stop()
+}
+
+func (s *Scheduler) stop() {
+	for _, timer := range s.timers {
+		timer.Stop()
+	}
+	s.timers = nil
+}
@@ -0,0 +1,234 @@
+package dbusapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"next-meeting/calendar"
+	"next-meeting/monitor"
+	"next-meeting/output"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/introspect"
+)
+
+// Names under which the service is published on the session bus
+const (
+	BusName    = "io.github.nextmeeting"
+	ObjectPath = dbus.ObjectPath("/io/github/nextmeeting")
+	Interface  = BusName
+
+	// SignalStatusChanged is emitted with the new state and the status as JSON
+	SignalStatusChanged = Interface + ".StatusChanged"
+
+	errorOffline = BusName + ".Error.Offline"
+)
+
+// signalInterval is how often the service checks whether the state changed
+const signalInterval = time.Second
+
+// refreshTimeout bounds how long the Refresh method may take
+const refreshTimeout = 30 * time.Second
+
+// introspection describes the interface for D-Bus tooling such as busctl or d-feet
+const introspection = `
+<node>
+	<interface name="` + Interface + `">
+		<method name="GetStatus">
+			<arg name="status" direction="out" type="s"/>
+		</method>
+		<method name="GetAgenda">
+			<arg name="agenda" direction="out" type="s"/>
+		</method>
+		<method name="Refresh"/>
+		<method name="Join">
+			<arg name="summary" direction="out" type="s"/>
+		</method>
+		<signal name="StatusChanged">
+			<arg name="state" type="s"/>
+			<arg name="status" type="s"/>
+		</signal>
+	</interface>` + introspect.IntrospectDataString + `</node>`
+
+// JoinFunc joins the meeting that is current or next in status
+type JoinFunc func(status *calendar.MeetingStatus) (*calendar.MeetingInfo, error)
+
+// Options configure the service
+type Options struct {
+	Soon      time.Duration    // A meeting starting within this is "soon"
+	IsOffline func(error) bool // Tells whether a refresh error means the calendar could not be reached
+	Join      JoinFunc
+}
+
+// Service publishes the meeting status computed from the events kept by a monitor
+type Service struct {
+	mon      *monitor.Monitor
+	opts     Options
+	interval time.Duration
+	ctx      context.Context
+}
+
+// NewService creates a service for mon
+func NewService(mon *monitor.Monitor, opts Options) *Service {
+	return &Service{mon: mon, opts: opts, interval: signalInterval, ctx: context.Background()}
+}
+
+// Serve publishes the service on conn and emits StatusChanged whenever the
+// state changes, until ctx is cancelled
+func (s *Service) Serve(ctx context.Context, conn *dbus.Conn) error {
+	s.ctx = ctx
+
+	if err := conn.Export(object{s}, ObjectPath, Interface); err != nil {
+		return fmt.Errorf("failed to export object: %w", err)
+	}
+	if err := conn.Export(introspect.Introspectable(introspection), ObjectPath, "org.freedesktop.DBus.Introspectable"); err != nil {
+		return fmt.Errorf("failed to export introspection: %w", err)
+	}
+
+	reply, err := conn.RequestName(BusName, dbus.NameFlagDoNotQueue)
+	if err != nil {
+		return fmt.Errorf("failed to request name %s: %w", BusName, err)
+	}
+	if reply != dbus.RequestNameReplyPrimaryOwner {
+		return fmt.Errorf("name %s is already taken", BusName)
+	}
+	defer conn.ReleaseName(BusName)
+
+	updates, unsubscribe := s.mon.Subscribe()
+	defer unsubscribe()
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	last := ""
+	for {
+		state, status, key := s.snapshot()
+		if key != last {
+			if err := conn.Emit(ObjectPath, SignalStatusChanged, state, status); err != nil {
+				return fmt.Errorf("failed to emit signal: %w", err)
+			}
+			last = key
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-updates:
+		case <-ticker.C:
+		}
+	}
+}
+
+// snapshot returns the state, the status as JSON and a key that changes
+// whenever the state does
+func (s *Service) snapshot() (string, string, string) {
+	events, err := s.events()
+	if err != nil {
+		code := errorCode(err)
+		state := output.StateError
+		if code == output.ErrorOffline {
+			state = output.StateOffline
+		}
+		return state, marshal(output.NewErrorStatus(code, err.Error())), state + "|" + err.Error()
+	}
+
+	now := s.mon.Now()
+	status := calendar.GetMeetingStatus(events, now)
+	state := output.State(status, now, s.opts.Soon)
+	return state, marshal(output.NewStatus(status, now, now.Sub(s.mon.FetchedAt()))), output.ChangeKey(status, now, s.opts.Soon)
+}
+
+// offlineError marks refresh failures caused by the calendar being unreachable
+type offlineError struct{ error }
+
+// events returns the events in memory, or an error if there are none because
+// loading them failed
+func (s *Service) events() ([]*calendar.MeetingInfo, error) {
+	events := s.mon.Events()
+	if err := s.mon.Err(); err != nil && events == nil {
+		if s.opts.IsOffline != nil && s.opts.IsOffline(err) {
+			return nil, offlineError{errors.New("Calendar offline")}
+		}
+		return nil, err
+	}
+	return events, nil
+}
+
+func errorCode(err error) string {
+	if errors.As(err, new(offlineError)) {
+		return output.ErrorOffline
+	}
+	return output.ErrorGeneric
+}
+
+func marshal(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
+// busError converts err to a D-Bus error
+func busError(err error) *dbus.Error {
+	if errors.As(err, new(offlineError)) {
+		return dbus.NewError(errorOffline, []any{err.Error()})
+	}
+	return dbus.MakeFailedError(err)
+}
+
+// object holds the methods exported on the bus
+type object struct {
+	s *Service
+}
+
+// GetStatus returns the status in the JSON output format
+func (o object) GetStatus() (string, *dbus.Error) {
+	events, err := o.s.events()
+	if err != nil {
+		return "", busError(err)
+	}
+	now := o.s.mon.Now()
+	return marshal(output.NewStatus(calendar.GetMeetingStatus(events, now), now, now.Sub(o.s.mon.FetchedAt()))), nil
+}
+
+// GetAgenda returns today's agenda in the JSON agenda format
+func (o object) GetAgenda() (string, *dbus.Error) {
+	events, err := o.s.events()
+	if err != nil {
+		return "", busError(err)
+	}
+	now := o.s.mon.Now()
+	return marshal(output.NewAgendaJSON(now, output.NewAgenda(events, now), now)), nil
+}
+
+// Refresh fetches events from the calendar now, bypassing the cache
+func (o object) Refresh() *dbus.Error {
+	ctx, cancel := context.WithTimeout(o.s.ctx, refreshTimeout)
+	defer cancel()
+	if err := o.s.mon.ForceRefresh(ctx); err != nil {
+		if o.s.opts.IsOffline != nil && o.s.opts.IsOffline(err) {
+			err = offlineError{err}
+		}
+		return busError(err)
+	}
+	return nil
+}
+
+// Join opens the conference link of the current or next meeting and returns its title
+func (o object) Join() (string, *dbus.Error) {
+	if o.s.opts.Join == nil {
+		return "", dbus.MakeFailedError(errors.New("joining is not supported"))
+	}
+	events, err := o.s.events()
+	if err != nil {
+		return "", busError(err)
+	}
+	meeting, err := o.s.opts.Join(calendar.GetMeetingStatus(events, o.s.mon.Now()))
+	if err != nil {
+		return "", dbus.MakeFailedError(err)
+	}
+	return meeting.Summary, nil
+}
@@ -0,0 +1,247 @@
+package dbusapi
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"next-meeting/calendar"
+	"next-meeting/clock"
+	"next-meeting/monitor"
+	"next-meeting/output"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// startBus runs a private session bus for the test and returns its address.
+// The test is skipped if dbus-daemon is not installed.
+func startBus(t *testing.T) string {
+	t.Helper()
+	path, err := exec.LookPath("dbus-daemon")
+	if err != nil {
+		t.Skip("dbus-daemon not installed")
+	}
+
+	cmd := exec.Command(path, "--session", "--nofork", "--print-address")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatalf("failed to get dbus-daemon output: %v", err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("failed to start dbus-daemon: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+
+	address, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		t.Fatalf("failed to read bus address: %v", err)
+	}
+	return strings.TrimSpace(address)
+}
+
+func connect(t *testing.T, address string) *dbus.Conn {
+	t.Helper()
+	conn, err := dbus.Connect(address)
+	if err != nil {
+		t.Fatalf("failed to connect to bus: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestService(t *testing.T) {
+	address := startBus(t)
+
+	fixedNow := time.Date(2026, 1, 9, 14, 30, 0, 0, time.UTC)
+	events := []*calendar.MeetingInfo{
+		{Summary: "Current", Start: fixedNow.Add(-time.Minute), End: fixedNow.Add(time.Hour), HangoutLink: "https://meet.google.com/abc"},
+		{Summary: "Next", Start: fixedNow.Add(2 * time.Hour), End: fixedNow.Add(3 * time.Hour)},
+	}
+	fetches := make(chan bool, 10)
+	fetch := func(ctx context.Context, force bool) ([]*calendar.MeetingInfo, time.Time, error) {
+		fetches <- force
+		return events, fixedNow, nil
+	}
+	mon := monitor.New(fetch, clock.Fixed(fixedNow), time.Minute)
+	_ = mon.Refresh(context.Background())
+	<-fetches
+
+	joined := make(chan *calendar.MeetingInfo, 1)
+	svc := NewService(mon, Options{
+		Soon: 10 * time.Minute,
+		Join: func(status *calendar.MeetingStatus) (*calendar.MeetingInfo, error) {
+			joined <- status.CurrentMeeting
+			return status.CurrentMeeting, nil
+		},
+	})
+
+	client := connect(t, address)
+	if err := client.AddMatchSignal(dbus.WithMatchInterface(Interface), dbus.WithMatchMember("StatusChanged")); err != nil {
+		t.Fatalf("AddMatchSignal failed: %v", err)
+	}
+	signals := make(chan *dbus.Signal, 10)
+	client.Signal(signals)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- svc.Serve(ctx, connect(t, address)) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Serve failed: %v", err)
+		}
+	})
+
+	select {
+	case sig := <-signals:
+		if sig.Name != SignalStatusChanged || len(sig.Body) != 2 || sig.Body[0] != output.StateInMeeting {
+			t.Errorf("unexpected signal: %+v", sig)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected an initial StatusChanged signal")
+	}
+
+	obj := client.Object(BusName, ObjectPath)
+
+	t.Run("GetStatus", func(t *testing.T) {
+		var data string
+		if err := obj.Call(Interface+".GetStatus", 0).Store(&data); err != nil {
+			t.Fatalf("GetStatus failed: %v", err)
+		}
+		var status output.Status
+		if err := json.Unmarshal([]byte(data), &status); err != nil {
+			t.Fatalf("invalid status JSON: %v", err)
+		}
+		if status.Current == nil || status.Current.Summary != "Current" {
+			t.Errorf("unexpected status: %s", data)
+		}
+	})
+
+	t.Run("GetAgenda", func(t *testing.T) {
+		var data string
+		if err := obj.Call(Interface+".GetAgenda", 0).Store(&data); err != nil {
+			t.Fatalf("GetAgenda failed: %v", err)
+		}
+		var agenda output.Agenda
+		if err := json.Unmarshal([]byte(data), &agenda); err != nil {
+			t.Fatalf("invalid agenda JSON: %v", err)
+		}
+		if len(agenda.Meetings) != 2 {
+			t.Errorf("expected 2 meetings, got %s", data)
+		}
+	})
+
+	t.Run("Refresh", func(t *testing.T) {
+		if err := obj.Call(Interface+".Refresh", 0).Err; err != nil {
+			t.Fatalf("Refresh failed: %v", err)
+		}
+		select {
+		case forced := <-fetches:
+			if !forced {
+				t.Errorf("expected Refresh to bypass the cache")
+			}
+		default:
+			t.Errorf("expected Refresh to fetch events")
+		}
+	})
+
+	t.Run("Join", func(t *testing.T) {
+		var summary string
+		if err := obj.Call(Interface+".Join", 0).Store(&summary); err != nil {
+			t.Fatalf("Join failed: %v", err)
+		}
+		if summary != "Current" {
+			t.Errorf("expected to join Current, got %q", summary)
+		}
+		select {
+		case meeting := <-joined:
+			if meeting == nil || meeting.Summary != "Current" {
+				t.Errorf("expected Join to be called with Current, got %v", meeting)
+			}
+		default:
+			t.Error("expected Join to be called")
+		}
+	})
+
+	t.Run("Introspect", func(t *testing.T) {
+		var xml string
+		if err := obj.Call("org.freedesktop.DBus.Introspectable.Introspect", 0).Store(&xml); err != nil {
+			t.Fatalf("Introspect failed: %v", err)
+		}
+		if !strings.Contains(xml, "StatusChanged") {
+			t.Errorf("expected introspection to describe StatusChanged, got %s", xml)
+		}
+	})
+}
+
+func TestServiceOffline(t *testing.T) {
+	address := startBus(t)
+
+	fetch := func(ctx context.Context, force bool) ([]*calendar.MeetingInfo, time.Time, error) {
+		return nil, time.Time{}, errors.New("offline")
+	}
+	mon := monitor.New(fetch, clock.System(), time.Minute)
+	_ = mon.Refresh(context.Background())
+
+	svc := NewService(mon, Options{IsOffline: func(err error) bool { return err.Error() == "offline" }})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = svc.Serve(ctx, connect(t, address)) }()
+
+	client := connect(t, address)
+	obj := client.Object(BusName, ObjectPath)
+
+	// Wait for the service to own its name
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var hasOwner bool
+		if err := client.BusObject().Call("org.freedesktop.DBus.NameHasOwner", 0, BusName).Store(&hasOwner); err == nil && hasOwner {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("service did not take its name")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	var data string
+	err := obj.Call(Interface+".GetStatus", 0).Store(&data)
+	var busErr dbus.Error
+	if !errors.As(err, &busErr) || busErr.Name != errorOffline {
+		t.Errorf("expected %s error, got %v", errorOffline, err)
+	}
+}
+
+func TestSnapshotState(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantState string
+	}{
+		{"offline", errors.New("offline"), output.StateOffline},
+		{"other error", errors.New("token expired"), output.StateError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetch := func(ctx context.Context, force bool) ([]*calendar.MeetingInfo, time.Time, error) {
+				return nil, time.Time{}, tt.err
+			}
+			mon := monitor.New(fetch, clock.System(), time.Minute)
+			_ = mon.Refresh(context.Background())
+			svc := NewService(mon, Options{IsOffline: func(err error) bool { return err.Error() == "offline" }})
+
+			if state, _, _ := svc.snapshot(); state != tt.wantState {
+				t.Errorf("state = %q, want %q", state, tt.wantState)
+			}
+		})
+	}
+}
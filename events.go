@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"next-meeting/auth"
+	"next-meeting/cache"
+	"next-meeting/calendar"
+	"next-meeting/clock"
+	"next-meeting/config"
+	"next-meeting/monitor"
+)
+
+// cacheKey describes the query used to fetch the events of the local day containing day
+func cacheKey(day time.Time) cache.Key {
+	return cache.Key{
+		Provider:  calendar.ProviderName,
+		Profile:   defaultProfile,
+		Calendars: []string{calendar.PrimaryCalendarID},
+		Window:    day.Format(time.DateOnly),
+	}
+}
+
+// loadEvents returns today's events from the cache, fetching them from the API
+// if there is no valid cache entry. It also returns when the events were fetched.
+func loadEvents(ctx context.Context, ttl time.Duration, clk clock.Clock) ([]*calendar.MeetingInfo, time.Time, error) {
+	return loadDayEvents(ctx, clk.Now(), ttl, clk)
+}
+
+// loadDayEvents is like loadEvents for the local day containing day
+func loadDayEvents(ctx context.Context, day time.Time, ttl time.Duration, clk clock.Clock) ([]*calendar.MeetingInfo, time.Time, error) {
+	key := cacheKey(day)
+	if entry := cache.ReadEntry(key, clk.Now()); entry != nil {
+		return entry.Events, entry.Timestamp, nil
+	}
+
+	events, err := fetchEvents(ctx, day, ttl, clk)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return events, clk.Now(), nil
+}
+
+// fetchEvents gets the events of the local day containing day from the API and caches them
+func fetchEvents(ctx context.Context, day time.Time, ttl time.Duration, clk clock.Clock) ([]*calendar.MeetingInfo, error) {
+	// Get authenticated client
+	client, err := auth.GetClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting authenticated client: %w", err)
+	}
+
+	// Create calendar service
+	calSvc, err := calendar.NewService(ctx, client, clk)
+	if err != nil {
+		return nil, fmt.Errorf("creating calendar service: %w", err)
+	}
+
+	// Get events from API
+	events, err := calSvc.GetDayEvents(ctx, day)
+	if err != nil {
+		return nil, fmt.Errorf("getting events: %w", err)
+	}
+
+	// Cache the events (non-fatal if it fails). Events fetched while
+	// replaying would be cached as of the wrong time.
+	if replay {
+		return events, nil
+	}
+	if err := cache.Write(cacheKey(day), events, ttl, clk.Now()); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to cache results: %v\n", err)
+	}
+	return events, nil
+}
+
+// exitOnLoadError reports an error from loadEvents and exits
+func exitOnLoadError(err error, cfg *config.Config) {
+	if isNetworkError(err) {
+		reportOffline(cfg)
+		os.Exit(1)
+	}
+	errorAndExit("Error %v\n", err)
+}
+
+// monitorRefreshInterval is how often long-running modes reload events. Reloads
+// are served from the cache until it expires.
+const monitorRefreshInterval = time.Minute
+
+// newMonitor creates a monitor that keeps today's events in memory
+func newMonitor(cfg *config.Config, clk clock.Clock, onlyAccepted bool) *monitor.Monitor {
+	fetch := func(ctx context.Context, force bool) ([]*calendar.MeetingInfo, time.Time, error) {
+		ttl := time.Duration(cfg.CacheTTL)
+		var events []*calendar.MeetingInfo
+		fetchedAt := clk.Now()
+		var err error
+		if force {
+			events, err = fetchEvents(ctx, fetchedAt, ttl, clk)
+		} else {
+			events, fetchedAt, err = loadEvents(ctx, ttl, clk)
+		}
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		if onlyAccepted {
+			events = calendar.FilterAccepted(events)
+		}
+		return events, fetchedAt, nil
+	}
+	return monitor.New(fetch, clk, monitorRefreshInterval)
+}
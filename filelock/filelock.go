@@ -0,0 +1,42 @@
+package filelock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// errLocked is returned by lock when not waiting and another process holds the lock
+var errLocked = errors.New("locked by another process")
+
+// Lock takes an exclusive lock for path, held on a sibling ".lock" file so
+// path itself can be replaced while locked. It waits until no other process
+// holds the lock and returns a function that releases it.
+func Lock(path string) (unlock func(), err error) {
+	unlock, _, err = take(path, true)
+	return unlock, err
+}
+
+// TryLock is like Lock but doesn't wait: ok is false if another process holds
+// the lock
+func TryLock(path string) (unlock func(), ok bool, err error) {
+	return take(path, false)
+}
+
+func take(path string, wait bool) (func(), bool, error) {
+	f, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to open lock file: %w", err)
+	}
+	if err := lock(f, wait); err != nil {
+		f.Close()
+		if errors.Is(err, errLocked) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+	return func() {
+		_ = release(f)
+		f.Close()
+	}, true, nil
+}
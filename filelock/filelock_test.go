@@ -0,0 +1,61 @@
+package filelock
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLockExcludes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	unlock, err := Lock(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	locked := make(chan func())
+	go func() {
+		unlock, err := Lock(path)
+		if err != nil {
+			t.Error(err)
+			close(locked)
+			return
+		}
+		locked <- unlock
+	}()
+
+	select {
+	case <-locked:
+		t.Fatal("expected the second lock to wait for the first")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	unlock()
+	select {
+	case unlock, ok := <-locked:
+		if ok {
+			unlock()
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the second lock once the first was released")
+	}
+}
+
+func TestTryLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox")
+	unlock, ok, err := TryLock(path)
+	if err != nil || !ok {
+		t.Fatalf("expected the lock, got %v (%v)", ok, err)
+	}
+
+	if _, ok, err := TryLock(path); err != nil || ok {
+		t.Fatalf("expected the lock to be busy, got %v (%v)", ok, err)
+	}
+
+	unlock()
+	unlock, ok, err = TryLock(path)
+	if err != nil || !ok {
+		t.Fatalf("expected the lock once released, got %v (%v)", ok, err)
+	}
+	unlock()
+}
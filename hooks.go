@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"next-meeting/calendar"
+	"next-meeting/config"
+	"next-meeting/hooks"
+	"next-meeting/monitor"
+	"next-meeting/transition"
+)
+
+// transitionInterval is how often the daemon checks for transitions
+const transitionInterval = time.Second
+
+// newTracker returns a tracker for status transitions, or nil if nothing
+// reacts to them
+func newTracker(cfg *config.Config) *transition.Tracker {
+	if len(cfg.Hooks) == 0 && !cfg.Slack && len(cfg.Webhooks) == 0 {
+		return nil
+	}
+	for name := range cfg.Hooks {
+		if !slices.Contains(transition.Kinds, transition.Kind(name)) {
+			fmt.Fprintf(os.Stderr, "Warning: unknown hook %q\n", name)
+		}
+	}
+	return transition.NewTracker(transition.GetPath(), time.Duration(cfg.SoonThreshold))
+}
+
+// observeTransitions detects what changed since the last observation, runs
+// the configured hooks, syncs the Slack status and delivers webhooks. It does
+// nothing if tracker is nil.
+func observeTransitions(ctx context.Context, cfg *config.Config, tracker *transition.Tracker, status *calendar.MeetingStatus, offline bool, now time.Time) {
+	if tracker == nil {
+		return
+	}
+
+	transitions, err := tracker.Observe(status, offline, now)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to update transition state: %v\n", err)
+	}
+
+	timeout := time.Duration(cfg.HookTimeout)
+	for _, err := range hooks.RunAll(ctx, cfg.Hooks, transitions, timeout) {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	if cfg.Slack {
+		if err := syncSlack(ctx, cfg, transitions); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+	if len(cfg.Webhooks) > 0 {
+		for _, err := range deliverWebhooks(ctx, cfg, transitions, now) {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+}
+
+// watchTransitions observes the status kept by mon every second until ctx is cancelled
+func watchTransitions(ctx context.Context, cfg *config.Config, tracker *transition.Tracker, mon *monitor.Monitor) {
+	ticker := time.NewTicker(transitionInterval)
+	defer ticker.Stop()
+
+	for {
+		err := mon.Err()
+		offline := err != nil && isNetworkError(err)
+		observeTransitions(ctx, cfg, tracker, mon.Status(), offline, mon.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
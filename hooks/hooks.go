@@ -0,0 +1,97 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"next-meeting/output"
+	"next-meeting/transition"
+)
+
+// Payload is the JSON document hooks receive on stdin
+type Payload struct {
+	Event    transition.Kind `json:"event"`
+	Time     time.Time       `json:"time"`
+	Meeting  *output.Meeting `json:"meeting"`            // null for day-free and offline
+	Previous *output.Meeting `json:"previous,omitempty"` // The meeting before it was moved
+}
+
+// NewPayload builds the payload describing t
+func NewPayload(t transition.Transition) *Payload {
+	return &Payload{
+		Event:    t.Kind,
+		Time:     t.At,
+		Meeting:  output.NewMeeting(t.Meeting, t.At),
+		Previous: output.NewMeeting(t.Previous, t.At),
+	}
+}
+
+// Env returns the environment variables describing t, in addition to the
+// ones the hook inherits
+func Env(t transition.Transition) []string {
+	env := []string{"NEXT_MEETING_EVENT=" + string(t.Kind)}
+	if m := t.Meeting; m != nil {
+		env = append(env,
+			"NEXT_MEETING_TITLE="+m.Summary,
+			"NEXT_MEETING_START="+m.Start.Format(time.RFC3339),
+			"NEXT_MEETING_END="+m.End.Format(time.RFC3339),
+			"NEXT_MEETING_LINK="+m.HangoutLink,
+			"NEXT_MEETING_LOCATION="+m.Location,
+			"NEXT_MEETING_CONFERENCE="+m.Conference,
+			"NEXT_MEETING_ATTENDEES="+strconv.Itoa(m.Attendees),
+		)
+	}
+	return env
+}
+
+// Run executes command with sh for transition t, passing the details as
+// environment variables and JSON on stdin. The command is killed after timeout.
+func Run(ctx context.Context, command string, t transition.Transition, timeout time.Duration) error {
+	payload, err := json.Marshal(NewPayload(t))
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Env = append(os.Environ(), Env(t)...)
+	cmd.Stdin = bytes.NewReader(payload)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = time.Second
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("%s hook timed out after %s", t.Kind, timeout)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%s hook failed: %w: %s", t.Kind, err, msg)
+		}
+		return fmt.Errorf("%s hook failed: %w", t.Kind, err)
+	}
+	return nil
+}
+
+// RunAll runs the hook configured for each transition, in order
+func RunAll(ctx context.Context, commands map[string]string, transitions []transition.Transition, timeout time.Duration) []error {
+	var errs []error
+	for _, t := range transitions {
+		command := commands[string(t.Kind)]
+		if command == "" {
+			continue
+		}
+		if err := Run(ctx, command, t, timeout); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
@@ -0,0 +1,111 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"next-meeting/calendar"
+	"next-meeting/transition"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 1, 9, 9, 0, 0, 0, time.UTC)
+	meeting := &calendar.MeetingInfo{
+		Summary:     "Standup",
+		Start:       now,
+		End:         now.Add(15 * time.Minute),
+		HangoutLink: "https://meet.google.com/abc",
+		Attendees:   5,
+	}
+	tr := transition.Transition{Kind: transition.MeetingStarted, Meeting: meeting, At: now}
+
+	command := `cat > "$OUT/stdin.json"; printf '%s|%s|%s' "$NEXT_MEETING_EVENT" "$NEXT_MEETING_TITLE" "$NEXT_MEETING_LINK" > "$OUT/env"`
+	t.Setenv("OUT", dir)
+	if err := Run(context.Background(), command, tr, time.Second); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	env, err := os.ReadFile(filepath.Join(dir, "env"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(env) != "meeting-started|Standup|https://meet.google.com/abc" {
+		t.Errorf("unexpected environment: %q", env)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "stdin.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var payload Payload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		t.Fatalf("invalid JSON on stdin: %v", err)
+	}
+	if payload.Event != transition.MeetingStarted || payload.Meeting == nil || payload.Meeting.Summary != "Standup" || payload.Meeting.Attendees != 5 {
+		t.Errorf("unexpected payload: %s", data)
+	}
+}
+
+func TestRunWithoutMeeting(t *testing.T) {
+	tr := transition.Transition{Kind: transition.DayFree, At: time.Now()}
+	if env := Env(tr); len(env) != 1 || env[0] != "NEXT_MEETING_EVENT=day-free" {
+		t.Errorf("unexpected environment: %v", env)
+	}
+	if err := Run(context.Background(), `grep -q '"meeting":null'`, tr, time.Second); err != nil {
+		t.Errorf("expected null meeting on stdin: %v", err)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	tr := transition.Transition{Kind: transition.Offline, At: time.Now()}
+
+	err := Run(context.Background(), "echo broken >&2; exit 3", tr, time.Second)
+	if err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("expected failure with stderr, got %v", err)
+	}
+
+	start := time.Now()
+	err = Run(context.Background(), "sleep 10", tr, 50*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected timeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("hook was not killed in time, took %v", elapsed)
+	}
+}
+
+func TestRunAll(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("OUT", dir)
+	commands := map[string]string{
+		"meeting-started": `echo "$NEXT_MEETING_EVENT" >> "$OUT/log"`,
+		"meeting-ended":   `echo "$NEXT_MEETING_EVENT" >> "$OUT/log"`,
+		"offline":         "exit 1",
+	}
+	now := time.Now()
+	transitions := []transition.Transition{
+		{Kind: transition.MeetingEnded, At: now},
+		{Kind: transition.MeetingSoon, At: now},
+		{Kind: transition.MeetingStarted, At: now},
+		{Kind: transition.Offline, At: now},
+	}
+
+	errs := RunAll(context.Background(), commands, transitions, time.Second)
+	if len(errs) != 1 {
+		t.Errorf("expected 1 error, got %v", errs)
+	}
+
+	log, err := os.ReadFile(filepath.Join(dir, "log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(log) != "meeting-ended\nmeeting-started\n" {
+		t.Errorf("unexpected hooks run: %q", log)
+	}
+}
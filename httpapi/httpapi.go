@@ -0,0 +1,254 @@
+package httpapi
+
+import (
+	"context"
+	"crypto/subtle"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"next-meeting/calendar"
+	"next-meeting/monitor"
+	"next-meeting/output"
+)
+
+// streamInterval is how often /events checks whether the state changed
+const streamInterval = time.Second
+
+//go:embed index.html
+var indexHTML []byte
+
+// Options configure the server
+type Options struct {
+	Token     string           // Required as bearer token or ?token= when set
+	Soon      time.Duration    // A meeting starting within this is "soon"
+	IsOffline func(error) bool // Tells whether a refresh error means the calendar could not be reached
+}
+
+// Server serves the meeting status computed from the events kept by a monitor
+type Server struct {
+	mon      *monitor.Monitor
+	opts     Options
+	interval time.Duration
+}
+
+// NewServer creates a server for mon
+func NewServer(mon *monitor.Monitor, opts Options) *Server {
+	return &Server{mon: mon, opts: opts, interval: streamInterval}
+}
+
+// Handler returns the HTTP handler serving all endpoints
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.handleIndex)
+	mux.HandleFunc("GET /status", s.handleStatus)
+	mux.HandleFunc("GET /next", s.handleNext)
+	mux.HandleFunc("GET /agenda", s.handleAgenda)
+	mux.HandleFunc("GET /events", s.handleEvents)
+	return s.authenticate(mux)
+}
+
+// CheckAddr makes sure the server is only reachable from this machine, unless
+// a token protects it
+func CheckAddr(addr, token string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid address %q: %w", addr, err)
+	}
+	if isLoopback(host) {
+		return nil
+	}
+	if token == "" {
+		return errors.New("a token is required to listen on a non-loopback address")
+	}
+	return nil
+}
+
+// Serve answers requests on addr until ctx is cancelled
+func (s *Server) Serve(ctx context.Context, addr string) error {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// isLoopback reports whether host names this machine
+func isLoopback(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// authenticate rejects requests without the configured token. The token may
+// also be given as a query parameter, since EventSource can't set headers.
+// Without a token only requests addressed to a loopback host are served, so
+// web pages can't read the status through DNS rebinding.
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	if s.opts.Token == "" {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			host, _, err := net.SplitHostPort(r.Host)
+			if err != nil {
+				host = strings.Trim(r.Host, "[]")
+			}
+			if !isLoopback(host) {
+				writeJSON(w, http.StatusForbidden, output.NewErrorStatus(output.ErrorGeneric, "invalid host"))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("token")
+		if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+			token = bearer
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.opts.Token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeJSON(w, http.StatusUnauthorized, output.NewErrorStatus(output.ErrorGeneric, "invalid or missing token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// snapshot returns the events in memory, or an error status if there are none
+// because loading them failed
+func (s *Server) snapshot() ([]*calendar.MeetingInfo, *output.Status) {
+	events := s.mon.Events()
+	if err := s.mon.Err(); err != nil && events == nil {
+		if s.opts.IsOffline != nil && s.opts.IsOffline(err) {
+			return nil, output.NewErrorStatus(output.ErrorOffline, "Calendar offline")
+		}
+		return nil, output.NewErrorStatus(output.ErrorGeneric, err.Error())
+	}
+	return events, nil
+}
+
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(indexHTML)
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	events, errStatus := s.snapshot()
+	if errStatus != nil {
+		writeJSON(w, http.StatusServiceUnavailable, errStatus)
+		return
+	}
+	now := s.mon.Now()
+	writeJSON(w, http.StatusOK, output.NewStatus(calendar.GetMeetingStatus(events, now), now, now.Sub(s.mon.FetchedAt())))
+}
+
+func (s *Server) handleNext(w http.ResponseWriter, r *http.Request) {
+	events, errStatus := s.snapshot()
+	if errStatus != nil {
+		writeJSON(w, http.StatusServiceUnavailable, errStatus)
+		return
+	}
+	now := s.mon.Now()
+	writeJSON(w, http.StatusOK, output.NewMeeting(calendar.GetMeetingStatus(events, now).NextMeeting, now))
+}
+
+func (s *Server) handleAgenda(w http.ResponseWriter, r *http.Request) {
+	events, errStatus := s.snapshot()
+	if errStatus != nil {
+		writeJSON(w, http.StatusServiceUnavailable, errStatus)
+		return
+	}
+	now := s.mon.Now()
+	writeJSON(w, http.StatusOK, output.NewAgendaJSON(now, output.NewAgenda(events, now), now))
+}
+
+// Event is sent on the /events stream whenever the state changes
+type Event struct {
+	State  string         `json:"state"`
+	Status *output.Status `json:"status"`
+}
+
+// event describes the current state, and returns a key that changes whenever
+// something other than the passing of time changes
+func (s *Server) event() (*Event, string) {
+	events, errStatus := s.snapshot()
+	if errStatus != nil {
+		state := output.StateError
+		if errStatus.Error.Code == output.ErrorOffline {
+			state = output.StateOffline
+		}
+		return &Event{State: state, Status: errStatus}, state + "|" + errStatus.Error.Message
+	}
+
+	now := s.mon.Now()
+	status := calendar.GetMeetingStatus(events, now)
+	ev := &Event{
+		State:  output.State(status, now, s.opts.Soon),
+		Status: output.NewStatus(status, now, now.Sub(s.mon.FetchedAt())),
+	}
+	return ev, output.ChangeKey(status, now, s.opts.Soon)
+}
+
+// handleEvents streams an event with the current state, then one each time it changes
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	updates, unsubscribe := s.mon.Subscribe()
+	defer unsubscribe()
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	last := ""
+	for {
+		ev, key := s.event()
+		if key != last {
+			data, err := json.Marshal(ev)
+			if err != nil {
+				return
+			}
+			if _, err := fmt.Fprintf(w, "event: status\ndata: %s\n\n", data); err != nil {
+				return
+			}
+			flusher.Flush()
+			last = key
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-updates:
+		case <-ticker.C:
+		}
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = output.WriteJSON(w, v)
+}
@@ -0,0 +1,271 @@
+package httpapi
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"next-meeting/calendar"
+	"next-meeting/clock"
+	"next-meeting/monitor"
+	"next-meeting/output"
+)
+
+func newTestServer(t *testing.T, events []*calendar.MeetingInfo, fetchErr error, now time.Time, token string) *httptest.Server {
+	t.Helper()
+	fetch := func(ctx context.Context, force bool) ([]*calendar.MeetingInfo, time.Time, error) {
+		return events, now, fetchErr
+	}
+	mon := monitor.New(fetch, clock.Fixed(now), time.Minute)
+	_ = mon.Refresh(context.Background())
+
+	s := NewServer(mon, Options{
+		Token:     token,
+		Soon:      10 * time.Minute,
+		IsOffline: func(err error) bool { return err.Error() == "offline" },
+	})
+	s.interval = 10 * time.Millisecond
+	srv := httptest.NewServer(s.Handler())
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func getJSON(t *testing.T, url string, v any) int {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s failed: %v", url, err)
+	}
+	defer resp.Body.Close()
+	if v != nil {
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			t.Fatalf("invalid JSON from %s: %v", url, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestEndpoints(t *testing.T) {
+	fixedNow := time.Date(2026, 1, 9, 14, 30, 0, 0, time.UTC)
+	events := []*calendar.MeetingInfo{
+		{Summary: "Current", Start: fixedNow.Add(-time.Minute), End: fixedNow.Add(time.Hour)},
+		{Summary: "Next", Start: fixedNow.Add(2 * time.Hour), End: fixedNow.Add(3 * time.Hour)},
+	}
+	srv := newTestServer(t, events, nil, fixedNow, "")
+
+	var status output.Status
+	if code := getJSON(t, srv.URL+"/status", &status); code != http.StatusOK {
+		t.Fatalf("/status returned %d", code)
+	}
+	if status.Current == nil || status.Current.Summary != "Current" || status.Next == nil || status.Next.Summary != "Next" {
+		t.Errorf("unexpected status: %+v", status)
+	}
+
+	var next output.Meeting
+	if code := getJSON(t, srv.URL+"/next", &next); code != http.StatusOK {
+		t.Fatalf("/next returned %d", code)
+	}
+	if next.Summary != "Next" || next.SecondsUntilStart != 7200 {
+		t.Errorf("unexpected next meeting: %+v", next)
+	}
+
+	var agenda output.Agenda
+	if code := getJSON(t, srv.URL+"/agenda", &agenda); code != http.StatusOK {
+		t.Fatalf("/agenda returned %d", code)
+	}
+	if agenda.Date != "2026-01-09" || len(agenda.Meetings) != 2 || !agenda.Meetings[0].Current {
+		t.Errorf("unexpected agenda: %+v", agenda)
+	}
+
+	resp, err := http.Get(srv.URL + "/")
+	if err != nil {
+		t.Fatalf("GET / failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") {
+		t.Errorf("expected HTML page, got %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	if code := getJSON(t, srv.URL+"/nope", nil); code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown path, got %d", code)
+	}
+}
+
+func TestOffline(t *testing.T) {
+	srv := newTestServer(t, nil, errors.New("offline"), time.Now(), "")
+
+	var status output.Status
+	if code := getJSON(t, srv.URL+"/status", &status); code != http.StatusServiceUnavailable {
+		t.Fatalf("/status returned %d, want 503", code)
+	}
+	if status.Error == nil || status.Error.Code != output.ErrorOffline {
+		t.Errorf("expected offline error, got %+v", status.Error)
+	}
+}
+
+func TestEventState(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantState string
+	}{
+		{"offline", errors.New("offline"), output.StateOffline},
+		{"other error", errors.New("token expired"), output.StateError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetch := func(ctx context.Context, force bool) ([]*calendar.MeetingInfo, time.Time, error) {
+				return nil, time.Time{}, tt.err
+			}
+			mon := monitor.New(fetch, clock.System(), time.Minute)
+			_ = mon.Refresh(context.Background())
+			s := NewServer(mon, Options{IsOffline: func(err error) bool { return err.Error() == "offline" }})
+
+			ev, _ := s.event()
+			if ev.State != tt.wantState {
+				t.Errorf("State = %q, want %q", ev.State, tt.wantState)
+			}
+		})
+	}
+}
+
+func TestToken(t *testing.T) {
+	srv := newTestServer(t, nil, nil, time.Now(), "secret")
+
+	tests := []struct {
+		name   string
+		header string
+		query  string
+		want   int
+	}{
+		{name: "missing", want: http.StatusUnauthorized},
+		{name: "wrong", header: "Bearer nope", want: http.StatusUnauthorized},
+		{name: "bearer", header: "Bearer secret", want: http.StatusOK},
+		{name: "query", query: "?token=secret", want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, srv.URL+"/status"+tt.query, nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Errorf("got %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
+
+func TestHost(t *testing.T) {
+	tests := []struct {
+		host  string
+		token string
+		want  int
+	}{
+		{host: "127.0.0.1:7878", want: http.StatusOK},
+		{host: "localhost:7878", want: http.StatusOK},
+		{host: "[::1]:7878", want: http.StatusOK},
+		{host: "localhost", want: http.StatusOK},
+		{host: "evil.example.com:7878", want: http.StatusForbidden},
+		{host: "192.168.1.10:7878", want: http.StatusForbidden},
+		{host: "evil.example.com:7878", token: "secret", want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			srv := newTestServer(t, nil, nil, time.Now(), tt.token)
+			req, _ := http.NewRequest(http.MethodGet, srv.URL+"/status", nil)
+			req.Host = tt.host
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Errorf("got %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckAddr(t *testing.T) {
+	tests := []struct {
+		addr    string
+		token   string
+		wantErr bool
+	}{
+		{"127.0.0.1:7878", "", false},
+		{"localhost:7878", "", false},
+		{"[::1]:7878", "", false},
+		{"0.0.0.0:7878", "", true},
+		{"0.0.0.0:7878", "secret", false},
+		{"192.168.1.10:7878", "", true},
+		{"7878", "", true},
+	}
+
+	for _, tt := range tests {
+		err := CheckAddr(tt.addr, tt.token)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("CheckAddr(%q, %q) error = %v, wantErr %v", tt.addr, tt.token, err, tt.wantErr)
+		}
+	}
+}
+
+func TestEventStream(t *testing.T) {
+	fixedNow := time.Date(2026, 1, 9, 14, 30, 0, 0, time.UTC)
+	events := []*calendar.MeetingInfo{
+		{Summary: "Standup", Start: fixedNow.Add(5 * time.Minute), End: fixedNow.Add(20 * time.Minute)},
+	}
+	srv := newTestServer(t, events, nil, fixedNow, "")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/events", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /events failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	var lines []string
+	for scanner.Scan() && scanner.Text() != "" {
+		lines = append(lines, scanner.Text())
+	}
+	if len(lines) != 2 || lines[0] != "event: status" {
+		t.Fatalf("unexpected first event: %q", lines)
+	}
+
+	var ev Event
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(lines[1], "data: ")), &ev); err != nil {
+		t.Fatalf("invalid event data: %v", err)
+	}
+	if ev.State != output.StateSoon || ev.Status.Next == nil || ev.Status.Next.Summary != "Standup" {
+		t.Errorf("unexpected event: %+v", ev)
+	}
+
+	// The clock is stopped, so nothing changes and no further event is sent
+	// until the request is cancelled
+	if scanner.Scan() {
+		t.Errorf("unexpected second event line: %q", scanner.Text())
+	}
+}
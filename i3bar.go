@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"next-meeting/config"
+	"next-meeting/monitor"
+	"next-meeting/notify"
+	"next-meeting/output"
+
+	"github.com/pkg/browser"
+)
+
+// runI3bar speaks the i3bar protocol on stdout, updating the block every second
+// from the events in memory, until ctx is cancelled
+func runI3bar(ctx context.Context, mon *monitor.Monitor, formatter *output.Formatter, cfg *config.Config) error {
+	// Anything the browser prints would corrupt the protocol stream
+	browser.Stdout = os.Stderr
+
+	clicks := make(chan *output.I3barClick)
+	go readI3barClicks(os.Stdin, clicks)
+
+	_ = mon.Refresh(ctx)
+	go mon.Run(ctx)
+
+	w := bufio.NewWriter(os.Stdout)
+	if err := json.NewEncoder(w).Encode(output.I3barHeader{Version: 1, ClickEvents: true}); err != nil {
+		return err
+	}
+	if _, err := w.WriteString("[\n"); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	separator := ""
+	for {
+		block, err := json.Marshal([]*output.I3barBlock{i3barBlock(mon, formatter, cfg)})
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "%s%s\n", separator, block); err != nil {
+			return err
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		separator = ","
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		case click := <-clicks:
+			handleI3barClick(mon, click)
+		}
+	}
+}
+
+// i3barBlock renders the current status from memory
+func i3barBlock(mon *monitor.Monitor, formatter *output.Formatter, cfg *config.Config) *output.I3barBlock {
+	if err := mon.Err(); err != nil && mon.Events() == nil {
+		text := "⚠ Calendar error"
+		if isNetworkError(err) {
+			text = "📡 Calendar Offline"
+		}
+		return &output.I3barBlock{Name: output.I3barBlockName, FullText: text}
+	}
+
+	now := mon.Now()
+	status := mon.Status()
+	text, err := formatter.Format(output.NewData(mon.Events(), status, now, dataOptions(cfg)))
+	if err != nil {
+		text = "⚠ " + err.Error()
+	}
+	return output.NewI3barBlock(text, status, now, time.Duration(cfg.SoonThreshold))
+}
+
+// readI3barClicks forwards click events from i3bar until r is closed
+func readI3barClicks(r io.Reader, clicks chan<- *output.I3barClick) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if click := output.ParseI3barClick(scanner.Text()); click != nil && click.Name == output.I3barBlockName {
+			clicks <- click
+		}
+	}
+}
+
+// handleI3barClick joins the meeting on left click and shows the agenda on right click
+func handleI3barClick(mon *monitor.Monitor, click *output.I3barClick) {
+	status := mon.Status()
+	switch click.Button {
+	case output.I3barButtonLeft:
+		if _, err := joinMeeting(status); err != nil {
+			_ = notify.SendMessage("Next Meeting", fmt.Sprintf("Cannot join: %v", err))
+		}
+	case output.I3barButtonRight:
+		agenda := strings.Join(output.AgendaLines(status), "\n")
+		if agenda == "" {
+			agenda = "No more meetings today"
+		}
+		_ = notify.SendMessage("📅 Today's agenda", agenda)
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/pkg/browser"
+
+	"next-meeting/calendar"
+)
+
+// meetingToJoin picks the meeting "join" opens: the current meeting if it has a
+// conference link, otherwise the next one
+func meetingToJoin(status *calendar.MeetingStatus) (*calendar.MeetingInfo, error) {
+	for _, meeting := range []*calendar.MeetingInfo{status.CurrentMeeting, status.NextMeeting} {
+		if meeting != nil && meeting.HangoutLink != "" {
+			return meeting, nil
+		}
+	}
+	return nil, errors.New("no meeting with a conference link")
+}
+
+// joinMeeting opens the conference link of the current or next meeting in the browser
+func joinMeeting(status *calendar.MeetingStatus) (*calendar.MeetingInfo, error) {
+	meeting, err := meetingToJoin(status)
+	if err != nil {
+		return nil, err
+	}
+	return meeting, browser.OpenURL(meeting.HangoutLink)
+}
@@ -1,7 +1,10 @@
 package keyring
 
 import (
+	"crypto/rand"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 
 	"github.com/zalando/go-keyring"
 	"golang.org/x/oauth2"
@@ -10,6 +13,11 @@ import (
 const (
 	serviceName = "next-meeting"
 	tokenKey    = "oauth-token"
+	dataKeyKey  = "data-key"
+	slackKey    = "slack-token"
+	mqttKey     = "mqtt-password"
+
+	dataKeySize = 32
 )
 
 // SaveToken stores the OAuth2 token in the system keyring
@@ -40,3 +48,75 @@ func LoadToken() (*oauth2.Token, error) {
 func DeleteToken() error {
 	return keyring.Delete(serviceName, tokenKey)
 }
+
+// LoadOrCreateDataKey returns the key used to encrypt local data,
+// generating and storing a new random key in the system keyring if none exists
+func LoadOrCreateDataKey() ([]byte, error) {
+	data, err := keyring.Get(serviceName, dataKeyKey)
+	if err == nil {
+		key, err := base64.StdEncoding.DecodeString(data)
+		if err != nil || len(key) != dataKeySize {
+			return nil, errors.New("stored data key is invalid")
+		}
+		return key, nil
+	}
+	if !errors.Is(err, keyring.ErrNotFound) {
+		return nil, err
+	}
+
+	key := make([]byte, dataKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := keyring.Set(serviceName, dataKeyKey, base64.StdEncoding.EncodeToString(key)); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// DeleteDataKey removes the data encryption key from the system keyring
+func DeleteDataKey() error {
+	err := keyring.Delete(serviceName, dataKeyKey)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// SaveSlackToken stores the Slack API token in the system keyring
+func SaveSlackToken(token string) error {
+	return keyring.Set(serviceName, slackKey, token)
+}
+
+// LoadSlackToken retrieves the Slack API token from the system keyring
+func LoadSlackToken() (string, error) {
+	return keyring.Get(serviceName, slackKey)
+}
+
+// DeleteSlackToken removes the Slack API token from the system keyring
+func DeleteSlackToken() error {
+	err := keyring.Delete(serviceName, slackKey)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// SaveMQTTPassword stores the MQTT broker password in the system keyring
+func SaveMQTTPassword(password string) error {
+	return keyring.Set(serviceName, mqttKey, password)
+}
+
+// LoadMQTTPassword retrieves the MQTT broker password from the system keyring
+func LoadMQTTPassword() (string, error) {
+	return keyring.Get(serviceName, mqttKey)
+}
+
+// DeleteMQTTPassword removes the MQTT broker password from the system keyring
+func DeleteMQTTPassword() error {
+	err := keyring.Delete(serviceName, mqttKey)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return nil
+	}
+	return err
+}
@@ -2,36 +2,177 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
 	"net"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
+	"text/tabwriter"
 	"time"
 
 	"next-meeting/auth"
 	"next-meeting/cache"
 	"next-meeting/calendar"
+	"next-meeting/clock"
+	"next-meeting/config"
+	"next-meeting/keyring"
 	"next-meeting/notify"
+	"next-meeting/output"
+	"next-meeting/transition"
+	"next-meeting/webhook"
 )
 
+// defaultProfile names the only account profile currently supported
+const defaultProfile = "default"
+
+// Output modes accepted by --output
+const (
+	outputPlain    = "plain"
+	outputJSON     = "json"
+	outputWaybar   = "waybar"
+	outputPolybar  = "polybar"
+	outputTmux     = "tmux"
+	outputArgos    = "argos"
+	outputMarkdown = "markdown"
+)
+
+// outputMode selects how results and errors are printed
+var outputMode = outputPlain
+
+// replay is set by --now. Runs then show another moment and leave nothing
+// behind that real runs would pick up: no cache entries, notifications or
+// notification markers.
+var replay bool
+
 func main() {
 	clearCredentials := flag.Bool("clear", false, "Clear credentials")
 	clearCache := flag.Bool("clear-cache", false, "Clear the calendar cache")
 	login := flag.Bool("login", false, "Login to Google Calendar")
 	onlyAccepted := flag.Bool("only-accepted", false, "Only show meetings you have accepted")
-	notifyThreshold := flag.String("notify", "", "Send notification if next meeting starts within this duration (e.g., 5m, 1h)")
+	notifyThreshold := flag.String("notify", "", "Send notification when a meeting starts within this duration, or each of a comma-separated list; \"reminders\" uses the meeting's own reminders, including ones at the start time (e.g., 5m, 10m,1m or reminders,1m)")
+	notifyConflicts := flag.Bool("notify-conflicts", false, "Send a notification once a day when meetings overlap")
+	backToBack := flag.Duration("back-to-back", 0, "Warn about back-to-back meetings longer than this (e.g., 2h)")
+	httpAddr := flag.String("http", "", "With daemon, also serve the status over HTTP on this address (e.g., 127.0.0.1:7878)")
+	mqttBroker := flag.String("mqtt", "", "With daemon, also publish the status to this MQTT broker (e.g., tcp://localhost:1883)")
+	dbusService := flag.Bool("dbus", false, "With daemon, also publish the status on the D-Bus session bus")
+	cacheTTL := flag.Duration("cache-ttl", 0, "How long to cache calendar events (default from config, or 30m)")
+	encryptCache := flag.Bool("encrypt-cache", false, "Encrypt cached events with a key stored in the system keyring")
+	format := flag.String("format", "", "Go text/template used to render the status line in every state")
+	showFree := flag.Bool("show-free", false, "Show how long you are free before the next meeting")
+	freeSlot := flag.Duration("free-slot", 0, "When booked solid, show the next free slot of at least this length (e.g., 30m)")
+	outputFlag := flag.String("output", outputPlain, "Output mode: plain, json, waybar, polybar, tmux, argos or markdown (agenda only)")
+	refresh := flag.Bool("refresh", false, "Ignore the cache and fetch events from the calendar")
+	watch := flag.Bool("watch", false, "Keep running and redraw the status line in place")
+	watchInterval := flag.Duration("watch-interval", time.Second, "How often --watch redraws the status line")
+	nowOverride := flag.String("now", "", "Pretend the current time is this (e.g., \"2026-01-09 13:59\"); for debugging")
+	flag.Usage = usage
 	flag.Parse()
 
 	ctx := context.Background()
 
+	switch *outputFlag {
+	case outputPlain, outputJSON, outputWaybar, outputPolybar, outputTmux, outputArgos, outputMarkdown:
+		outputMode = *outputFlag
+	default:
+		errorAndExit("%v\n", fmt.Errorf("unknown output mode %q", *outputFlag))
+	}
+
+	clk := clock.System()
+	if *nowOverride != "" {
+		t, err := clock.Parse(*nowOverride, time.Now())
+		if err != nil {
+			errorAndExit("Invalid --now value: %v\n", err)
+		}
+		clk = clock.StartingAt(t)
+		replay = true
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	if *cacheTTL > 0 {
+		cfg.CacheTTL = config.Duration(*cacheTTL)
+	}
+	if *encryptCache {
+		cfg.EncryptCache = true
+	}
+	if *format != "" {
+		cfg.Format = *format
+		cfg.FormatInMeeting, cfg.FormatUpcoming, cfg.FormatEmpty = "", "", ""
+	}
+	if *notifyConflicts {
+		cfg.NotifyConflicts = true
+	}
+	if *backToBack > 0 {
+		cfg.BackToBackLimit = config.Duration(*backToBack)
+	}
+	if *httpAddr != "" {
+		cfg.HTTPAddr = *httpAddr
+	}
+	if *mqttBroker != "" {
+		cfg.MQTTBroker = *mqttBroker
+	}
+	if *dbusService {
+		cfg.DBus = true
+	}
+	if *showFree {
+		cfg.ShowFree = true
+	}
+	if *freeSlot > 0 {
+		cfg.FreeSlot = config.Duration(*freeSlot)
+	}
+	cache.SetEncryption(cfg.EncryptCache)
+	notify.SetEncryption(cfg.EncryptCache)
+	transition.SetEncryption(cfg.EncryptCache)
+	webhook.SetEncryption(cfg.EncryptCache)
+
+	formatter, err := output.NewFormatter(templatesFromConfig(cfg))
+	if err != nil {
+		errorAndExit("Error in output format: %v\n", err)
+	}
+
+	command := flag.Arg(0)
+	switch command {
+	case "", "cache", "join", "i3bar", "agenda", "daemon", "slack", "mqtt":
+	default:
+		errorAndExit("%v\n", fmt.Errorf("unknown command %q", command))
+	}
+	if outputMode == outputMarkdown && command != "agenda" {
+		errorAndExit("%v\n", errors.New("markdown output is only supported by the agenda command"))
+	}
+
+	// Handle "cache" subcommand
+	if command == "cache" {
+		runCacheCommand(flag.Args()[1:], clk.Now())
+		return
+	}
+
+	// Handle "slack" subcommand
+	if command == "slack" {
+		runSlackCommand(ctx, cfg, flag.Args()[1:])
+		return
+	}
+
+	// Handle "mqtt" subcommand
+	if command == "mqtt" {
+		runMQTTCommand(flag.Args()[1:])
+		return
+	}
+
 	// Handle --clear-cache flag
 	if *clearCache {
 		if err := cache.Clear(); err != nil {
 			errorAndExit("Error clearing cache: %v\n", err)
 		}
 		_ = notify.Clear()
+		_ = os.Remove(transition.GetPath())
+		_ = os.RemoveAll(webhook.GetPath())
+		_ = keyring.DeleteDataKey()
 		fmt.Printf("✓ Cache cleared (%s)\n", cache.GetPath())
 		return
 	}
@@ -54,18 +195,117 @@ func main() {
 		return
 	}
 
+	// Let a running daemon answer plain status and join requests, so nothing
+	// has to be read from disk or fetched
+	var events []*calendar.MeetingInfo
+	var cachedAt time.Time
+	fromDaemon := false
+	if *nowOverride == "" && !*watch && (command == "" || command == "join") {
+		if resp, ok := queryDaemon(*refresh); ok {
+			if resp.Events == nil && resp.Error != "" {
+				if resp.Offline {
+					reportOffline(cfg)
+					os.Exit(1)
+				}
+				errorAndExit("Error %v\n", errors.New(resp.Error))
+			}
+			events, cachedAt, fromDaemon = resp.Events, resp.FetchedAt, true
+			if events == nil {
+				events = []*calendar.MeetingInfo{}
+			}
+		}
+	}
+
 	// Check if logged in
-	if !auth.IsLoggedIn(ctx) {
-		fmt.Println("🔒 Not logged in")
+	if !fromDaemon && !auth.IsLoggedIn(ctx) {
+		reportNotLoggedIn(cfg)
 		os.Exit(0)
 	}
 
-	// Try to read events from cache first
-	events := cache.Read()
+	// Handle --watch flag, which keeps running and redraws the status
+	if *watch {
+		if outputMode == outputArgos {
+			errorAndExit("%v\n", errors.New("--watch does not support argos output"))
+		}
+		ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		mon := newMonitor(cfg, clk, *onlyAccepted)
+		if err := runWatch(ctx, os.Stdout, mon, formatter, cfg, *watchInterval); err != nil {
+			errorAndExit("Error writing output: %v\n", err)
+		}
+		return
+	}
+
+	// Handle "agenda" subcommand
+	if command == "agenda" {
+		runAgenda(ctx, flag.Args()[1:], cfg, clk, *onlyAccepted, *refresh)
+		return
+	}
+
+	// Handle "i3bar" subcommand, which keeps running and streams updates
+	if command == "i3bar" {
+		ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		mon := newMonitor(cfg, clk, *onlyAccepted)
+		if err := runI3bar(ctx, mon, formatter, cfg); err != nil {
+			errorAndExit("Error writing i3bar output: %v\n", err)
+		}
+		return
+	}
+
+	// Handle "daemon" subcommand, which keeps running and serves other invocations
+	if command == "daemon" {
+		var thresholds notify.Thresholds
+		if *notifyThreshold != "" {
+			if thresholds, err = notify.ParseThresholds(*notifyThreshold); err != nil {
+				errorAndExit("Invalid notify duration: %v\n", err)
+			}
+		}
+		ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		mon := newMonitor(cfg, clk, *onlyAccepted)
+		if err := runDaemon(ctx, cfg, mon, clk, thresholds); err != nil {
+			errorAndExit("Error running daemon: %v\n", err)
+		}
+		return
+	}
+
+	// Hooks react to what changed since the last run. A running daemon observes
+	// transitions itself, and replaying a moment must not fire them.
+	var tracker *transition.Tracker
+	if command == "" && !fromDaemon && *nowOverride == "" {
+		tracker = newTracker(cfg)
+	}
+	failLoad := func(err error) {
+		if isNetworkError(err) {
+			observeTransitions(ctx, cfg, tracker, nil, true, clk.Now())
+		}
+		exitOnLoadError(err, cfg)
+	}
 
-	// If no valid cache, fetch from API
+	// When replaying a moment with --now, use whatever was cached for that day
+	// even if it has expired since
+	if *nowOverride != "" {
+		if entry, err := cache.Inspect(cacheKey(clk.Now()).Hash()); err == nil {
+			events, cachedAt = entry.Events, entry.Timestamp
+		}
+	}
+
+	// Handle --refresh flag
+	if *refresh && events == nil {
+		events, err = fetchEvents(ctx, clk.Now(), time.Duration(cfg.CacheTTL), clk)
+		if err != nil {
+			failLoad(err)
+		}
+		cachedAt = clk.Now()
+	}
+
+	// Read events from cache, or fetch them from the API if there's no valid cache
 	if events == nil {
-		events = getAndCacheEvents(ctx)
+		events, cachedAt, err = loadEvents(ctx, time.Duration(cfg.CacheTTL), clk)
+		if err != nil {
+			failLoad(err)
+		}
 	}
 
 	// Filter to only accepted events if requested
@@ -74,102 +314,325 @@ func main() {
 	}
 
 	// Calculate current/next meeting status from events (always fresh calculation)
-	status := calendar.GetMeetingStatus(events, time.Now())
+	now := clk.Now()
+	status := calendar.GetMeetingStatus(events, now)
+
+	// Handle "join" subcommand
+	if command == "join" {
+		meeting, err := joinMeeting(status)
+		if err != nil {
+			errorAndExit("Error joining meeting: %v\n", err)
+		}
+		fmt.Printf("✓ Joining %s\n", meeting.Summary)
+		return
+	}
+
+	observeTransitions(ctx, cfg, tracker, status, false, now)
 
 	// Handle --notify flag
-	if *notifyThreshold != "" {
-		threshold, err := time.ParseDuration(*notifyThreshold)
+	if *notifyThreshold != "" && !replay {
+		thresholds, err := notify.ParseThresholds(*notifyThreshold)
 		if err != nil {
 			errorAndExit("Invalid notify duration: %v\n", err)
 		}
 
 		// Clean old notification tracking files
-		notify.CleanOldNotifications()
+		notify.CleanOldNotifications(now)
 
 		// Check if we should send a notification
-		if meeting := notify.ShouldNotify(status, threshold); meeting != nil {
-			startsIn := time.Until(meeting.Start)
-			if err := notify.SendNotification(meeting, startsIn); err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: failed to send notification: %v\n", err)
-			} else {
-				// Mark as notified so we don't notify again
-				if err := notify.MarkNotified(meeting); err != nil {
-					fmt.Fprintf(os.Stderr, "Warning: failed to mark notification: %v\n", err)
-				}
-			}
+		for _, alerts := range notify.GroupByStart(notify.ShouldNotify(status, thresholds, now)) {
+			sendMeetingNotification(alerts, alerts[0].Meeting.Start.Sub(now))
+		}
+
+		// Warn before a long run of back-to-back meetings starts
+		if cfg.BackToBackLimit > 0 {
+			sendChainWarning(cfg, events, status, thresholds, now)
 		}
 	}
 
-	parts := buildParts(status)
+	// Announce double-booked days once, on the first run of the day
+	if cfg.NotifyConflicts && !replay {
+		sendConflictWarning(events, now)
+	}
 
 	// Output
-	if len(parts) == 0 {
-		fmt.Println("📭 No meetings")
-	} else {
-		fmt.Println(strings.Join(parts, " │ "))
+	text, err := renderStatus(formatter, cfg, events, status, now, cachedAt)
+	if err != nil {
+		errorAndExit("Error formatting output: %v\n", err)
 	}
+	fmt.Println(text)
 }
 
-func buildParts(status *calendar.MeetingStatus) []string {
-	now := time.Now()
+// renderStatus renders the meeting status in the selected output mode
+func renderStatus(formatter *output.Formatter, cfg *config.Config, events []*calendar.MeetingInfo, status *calendar.MeetingStatus, now, cachedAt time.Time) (string, error) {
+	if outputMode == outputJSON {
+		return marshalJSON(output.NewStatus(status, now, now.Sub(cachedAt)))
+	}
 
-	// Build single-line output
-	var parts []string
+	parts, err := formatter.Parts(output.NewData(events, status, now, dataOptions(cfg)))
+	if err != nil {
+		return "", err
+	}
+	line := strings.Join(parts, output.Separator)
 
-	// Current meeting (if any)
-	if status.CurrentMeeting != nil {
-		remaining := status.CurrentMeeting.End.Sub(now)
-		if remaining < time.Minute {
-			parts = append(parts, fmt.Sprintf("🔴 %s finishing now", status.CurrentMeeting.Summary))
-		} else {
-			parts = append(parts, fmt.Sprintf("🔴 %s (%s left)", status.CurrentMeeting.Summary, calendar.FormatDuration(remaining)))
-		}
+	soon := time.Duration(cfg.SoonThreshold)
+	switch outputMode {
+	case outputWaybar:
+		return marshalJSON(output.NewWaybar(line, status, now, soon))
+	case outputPolybar, outputTmux:
+		return markup(output.Segments(parts, status, now, soon), cfg), nil
+	case outputArgos:
+		return strings.TrimSuffix(output.Argos(line, status, selfCommand()), "\n"), nil
+	default:
+		return line, nil
 	}
+}
 
-	// Next meeting (if any)
-	if status.NextMeeting != nil {
-		startsIn := status.NextMeeting.Start.Sub(now)
-		if startsIn < time.Minute {
-			parts = append(parts, fmt.Sprintf("🕐 %s starting now", status.NextMeeting.Summary))
-		} else {
-			parts = append(parts, fmt.Sprintf("🕐 %s in %s", status.NextMeeting.Summary, calendar.FormatDuration(startsIn)))
-		}
+// reportNotLoggedIn tells the user that there are no valid credentials
+func reportNotLoggedIn(cfg *config.Config) {
+	const text = "🔒 Not logged in"
+	switch outputMode {
+	case outputJSON:
+		writeJSON(output.NewErrorStatus(output.ErrorNotLoggedIn, "Not logged in"))
+	case outputWaybar:
+		writeJSON(output.NewWaybarState(text, output.StateLoggedOut))
+	case outputPolybar, outputTmux:
+		fmt.Println(markup([]output.Segment{{Text: text, State: output.StateLoggedOut}}, cfg))
+	case outputArgos:
+		fmt.Print(output.ArgosState(text, selfCommand()))
+	default:
+		fmt.Println(text)
 	}
-	return parts
 }
 
-func getAndCacheEvents(ctx context.Context) []*calendar.MeetingInfo {
-	// Get authenticated client
-	client, err := auth.GetClient(ctx)
-	if err != nil {
-		errorAndExit("Error getting authenticated client: %v\n", err)
+// reportOffline tells the user that the calendar could not be reached
+func reportOffline(cfg *config.Config) {
+	if outputMode == outputPlain {
+		fmt.Fprintln(os.Stderr, renderOffline(cfg))
+		return
 	}
+	fmt.Println(renderOffline(cfg))
+}
 
-	// Create calendar service
-	calSvc, err := calendar.NewService(ctx, client)
+// renderOffline renders the offline state in the selected output mode
+func renderOffline(cfg *config.Config) string {
+	const text = "📡 Calendar Offline"
+	var rendered string
+	var err error
+	switch outputMode {
+	case outputJSON:
+		rendered, err = marshalJSON(output.NewErrorStatus(output.ErrorOffline, "Calendar Offline"))
+	case outputWaybar:
+		rendered, err = marshalJSON(output.NewWaybarState(text, output.StateOffline))
+	case outputPolybar, outputTmux:
+		rendered = markup([]output.Segment{{Text: text, State: output.StateOffline}}, cfg)
+	case outputArgos:
+		rendered = strings.TrimSuffix(output.ArgosState(text, selfCommand()), "\n")
+	default:
+		rendered = text
+	}
 	if err != nil {
-		errorAndExit("Error creating calendar service: %v\n", err)
+		panic(err)
 	}
+	return rendered
+}
+
+// markup renders segments for the polybar or tmux output modes
+func markup(segments []output.Segment, cfg *config.Config) string {
+	if outputMode == outputTmux {
+		return output.Tmux(segments, cfg.Colors)
+	}
+	return output.Polybar(segments, cfg.Colors, output.ShellQuote(selfCommand())+" join")
+}
 
-	// Get events from API
-	events, err := calSvc.GetTodayEvents(ctx)
+// selfCommand returns how to invoke this binary from status bar actions
+func selfCommand() string {
+	exe, err := os.Executable()
 	if err != nil {
-		if isNetworkError(err) {
-			fmt.Fprintln(os.Stderr, "📡 Calendar Offline")
-			os.Exit(1)
+		return "next-meeting"
+	}
+	return exe
+}
+
+// chainThreshold returns how long before a chain of back-to-back meetings to
+// warn about it: the largest threshold of its first meeting, or 0 for none
+func chainThreshold(thresholds notify.Thresholds, chain *calendar.Chain) time.Duration {
+	if forMeeting := thresholds.For(chain.Meetings[0]); len(forMeeting) > 0 {
+		return forMeeting[0]
+	}
+	return 0
+}
+
+// sendChainWarning warns once before a long run of back-to-back meetings starts
+func sendChainWarning(cfg *config.Config, events []*calendar.MeetingInfo, status *calendar.MeetingStatus, thresholds notify.Thresholds, now time.Time) {
+	defer lockNotifications()()
+
+	chains := calendar.BackToBackChains(events, time.Duration(cfg.MinBreak))
+	chain := calendar.LongChainAt(chains, time.Duration(cfg.BackToBackLimit), now, status.NextMeeting)
+	if chain == nil || !notify.ShouldNotifyChain(chain, chainThreshold(thresholds, chain), now) {
+		return
+	}
+	if err := notify.SendChainNotification(chain, chain.Start.Sub(now)); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to send notification: %v\n", err)
+	} else if err := notify.MarkChainNotified(chain); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to mark notification: %v\n", err)
+	}
+}
+
+// sendConflictWarning announces the double-bookings of the day once
+func sendConflictWarning(events []*calendar.MeetingInfo, now time.Time) {
+	defer lockNotifications()()
+
+	conflicts := notify.ShouldNotifyConflicts(events, now)
+	if len(conflicts) == 0 {
+		return
+	}
+	if err := notify.SendConflictNotification(conflicts); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to send notification: %v\n", err)
+	} else if err := notify.MarkConflictsNotified(now); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to mark notification: %v\n", err)
+	}
+}
+
+// dataOptions returns the template data options set in the config
+func dataOptions(cfg *config.Config) output.Options {
+	return output.Options{
+		FreeSlot:        time.Duration(cfg.FreeSlot),
+		BackToBackLimit: time.Duration(cfg.BackToBackLimit),
+		MinBreak:        time.Duration(cfg.MinBreak),
+	}
+}
+
+// templatesFromConfig picks the output template for each state, falling back
+// to the generic format and then to the default status line
+func templatesFromConfig(cfg *config.Config) output.Templates {
+	templates := output.DefaultTemplates()
+	if cfg.ShowFree {
+		templates.Upcoming += output.FreeSegment
+	}
+	if cfg.FreeSlot > 0 {
+		templates.InMeeting += output.FreeSlotSegment
+		templates.Upcoming += output.FreeSlotSegment
+	}
+	if cfg.Format != "" {
+		templates = output.Templates{InMeeting: cfg.Format, Upcoming: cfg.Format, Empty: cfg.Format}
+	}
+	if cfg.FormatInMeeting != "" {
+		templates.InMeeting = cfg.FormatInMeeting
+	}
+	if cfg.FormatUpcoming != "" {
+		templates.Upcoming = cfg.FormatUpcoming
+	}
+	if cfg.FormatEmpty != "" {
+		templates.Empty = cfg.FormatEmpty
+	}
+	return templates
+}
+
+// runCacheCommand handles the "cache list" and "cache inspect <hash>" subcommands
+func runCacheCommand(args []string, now time.Time) {
+	if len(args) == 0 {
+		errorAndExit("%v\n", errors.New("usage: next-meeting cache list|inspect <hash>"))
+	}
+
+	switch args[0] {
+	case "list":
+		entries, err := cache.List()
+		if err != nil {
+			errorAndExit("Error listing cache: %v\n", err)
 		}
-		errorAndExit("Error getting events: %v\n", err)
+		if len(entries) == 0 {
+			fmt.Println("📭 Cache is empty")
+			return
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "HASH\tPROVIDER\tPROFILE\tCALENDARS\tWINDOW\tEVENTS\tCACHED\tEXPIRES")
+		for _, entry := range entries {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+				entry.Hash(),
+				entry.Key.Provider,
+				entry.Key.Profile,
+				strings.Join(entry.Key.Calendars, ","),
+				entry.Key.Window,
+				len(entry.Events),
+				entry.Timestamp.Format(time.DateTime),
+				formatExpiry(entry, now),
+			)
+		}
+		_ = w.Flush()
+	case "inspect":
+		if len(args) < 2 {
+			errorAndExit("%v\n", errors.New("usage: next-meeting cache inspect <hash>"))
+		}
+		entry, err := cache.Inspect(args[1])
+		if err != nil {
+			errorAndExit("Error inspecting cache: %v\n", err)
+		}
+		fmt.Printf("Hash:      %s\n", entry.Hash())
+		fmt.Printf("Provider:  %s\n", entry.Key.Provider)
+		fmt.Printf("Profile:   %s\n", entry.Key.Profile)
+		fmt.Printf("Calendars: %s\n", strings.Join(entry.Key.Calendars, ", "))
+		fmt.Printf("Window:    %s\n", entry.Key.Window)
+		fmt.Printf("Cached:    %s\n", entry.Timestamp.Format(time.DateTime))
+		fmt.Printf("Expires:   %s\n", formatExpiry(entry, now))
+		fmt.Printf("Events:    %d\n", len(entry.Events))
+		for _, event := range entry.Events {
+			fmt.Printf("  %s-%s  %s\n", event.Start.Format("15:04"), event.End.Format("15:04"), event.Summary)
+		}
+	default:
+		errorAndExit("%v\n", fmt.Errorf("unknown cache command %q", args[0]))
 	}
+}
 
-	// Cache the events (non-fatal if it fails)
-	if err := cache.Write(events); err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: failed to cache results: %v\n", err)
+// formatExpiry describes when a cache entry expires
+func formatExpiry(entry *cache.CachedData, now time.Time) string {
+	if entry.Expired(now) {
+		return "expired"
 	}
-	return events
+	return fmt.Sprintf("%s (in %s)", entry.ExpiresAt.Format(time.DateTime), calendar.FormatDuration(entry.ExpiresAt.Sub(now)))
 }
 
-// errorAndExit prints an error message to stderr and exits the program
+// hiddenFlags are debugging flags left out of the usage message
+var hiddenFlags = map[string]bool{
+	"now": true,
+}
+
+// usage prints the usage message without hidden flags
+func usage() {
+	visible := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	visible.SetOutput(flag.CommandLine.Output())
+	flag.VisitAll(func(f *flag.Flag) {
+		if hiddenFlags[f.Name] {
+			return
+		}
+		visible.Var(f.Value, f.Name, f.Usage)
+		visible.Lookup(f.Name).DefValue = f.DefValue
+	})
+
+	fmt.Fprintf(visible.Output(), "Usage of %s:\n", os.Args[0])
+	visible.PrintDefaults()
+}
+
+// marshalJSON encodes v as a single line of JSON
+func marshalJSON(v any) (string, error) {
+	data, err := json.Marshal(v)
+	return string(data), err
+}
+
+// writeJSON prints v as JSON to stdout
+func writeJSON(v any) {
+	if err := output.WriteJSON(os.Stdout, v); err != nil {
+		panic(err)
+	}
+}
+
+// errorAndExit prints an error message to stderr and exits the program.
+// In JSON output mode the error is printed to stdout as a JSON status instead.
 func errorAndExit(format string, err error) {
+	if outputMode == outputJSON {
+		writeJSON(output.NewErrorStatus(output.ErrorGeneric, strings.TrimSpace(fmt.Sprintf(format, err))))
+		os.Exit(1)
+	}
 	_, printErr := fmt.Fprintf(os.Stderr, format, err)
 	if printErr != nil {
 		panic(printErr)
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"next-meeting/config"
+	"next-meeting/output"
+)
+
+func TestTemplatesFromConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		showFree bool
+		freeSlot time.Duration
+		wantFree bool
+		wantSlot bool
+	}{
+		{"default", false, 0, false, false},
+		{"show free", true, 0, true, false},
+		{"free slot", false, 30 * time.Minute, false, true},
+		{"both", true, 30 * time.Minute, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.ShowFree = tt.showFree
+			cfg.FreeSlot = config.Duration(tt.freeSlot)
+			templates := templatesFromConfig(cfg)
+
+			if got := strings.Contains(templates.Upcoming, output.FreeSegment); got != tt.wantFree {
+				t.Errorf("Upcoming has free segment = %v, want %v", got, tt.wantFree)
+			}
+			if got := strings.Contains(templates.Upcoming, output.FreeSlotSegment); got != tt.wantSlot {
+				t.Errorf("Upcoming has free slot segment = %v, want %v", got, tt.wantSlot)
+			}
+			if got := strings.Contains(templates.InMeeting, output.FreeSlotSegment); got != tt.wantSlot {
+				t.Errorf("InMeeting has free slot segment = %v, want %v", got, tt.wantSlot)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package monitor
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"next-meeting/calendar"
+	"next-meeting/clock"
+)
+
+// FetchFunc loads today's events, e.g. from the cache or the calendar API, and
+// returns when they were fetched. With force set it bypasses any cache.
+type FetchFunc func(ctx context.Context, force bool) ([]*calendar.MeetingInfo, time.Time, error)
+
+// Monitor keeps today's events in memory for long-running modes and refreshes
+// them in the background
+type Monitor struct {
+	fetch    FetchFunc
+	clock    clock.Clock
+	interval time.Duration
+
+	mu          sync.RWMutex
+	events      []*calendar.MeetingInfo
+	fetchedAt   time.Time
+	err         error
+	subscribers map[chan struct{}]struct{}
+}
+
+// New creates a monitor that calls fetch every interval
+func New(fetch FetchFunc, clk clock.Clock, interval time.Duration) *Monitor {
+	return &Monitor{
+		fetch:    fetch,
+		clock:    clk,
+		interval: interval,
+	}
+}
+
+// Refresh loads events now, possibly from a cache. On failure the previous
+// events are kept.
+func (m *Monitor) Refresh(ctx context.Context) error {
+	return m.refresh(ctx, false)
+}
+
+// ForceRefresh is like Refresh but bypasses any cache
+func (m *Monitor) ForceRefresh(ctx context.Context) error {
+	return m.refresh(ctx, true)
+}
+
+func (m *Monitor) refresh(ctx context.Context, force bool) error {
+	events, fetchedAt, err := m.fetch(ctx, force)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.err = err
+	if err != nil {
+		return err
+	}
+	m.events = events
+	m.fetchedAt = fetchedAt
+
+	for ch := range m.subscribers {
+		select {
+		case ch <- struct{}{}:
+		default: // A signal is already pending
+		}
+	}
+	return nil
+}
+
+// Subscribe returns a channel that receives a signal after each successful
+// refresh, and a function that stops the subscription. Signals are coalesced
+// when the subscriber is slow.
+func (m *Monitor) Subscribe() (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.subscribers == nil {
+		m.subscribers = make(map[chan struct{}]struct{})
+	}
+	m.subscribers[ch] = struct{}{}
+
+	return ch, func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		delete(m.subscribers, ch)
+	}
+}
+
+// Run refreshes events every interval until ctx is cancelled
+func (m *Monitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = m.Refresh(ctx)
+		}
+	}
+}
+
+// Events returns the events from the last successful refresh
+func (m *Monitor) Events() []*calendar.MeetingInfo {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.events
+}
+
+// FetchedAt returns when the events in memory were fetched
+func (m *Monitor) FetchedAt() time.Time {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.fetchedAt
+}
+
+// Err returns the error of the last refresh, or nil if it succeeded
+func (m *Monitor) Err() error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.err
+}
+
+// Now returns the current time according to the monitor's clock
+func (m *Monitor) Now() time.Time {
+	return m.clock.Now()
+}
+
+// Status calculates the meeting status at the current time from the events in memory
+func (m *Monitor) Status() *calendar.MeetingStatus {
+	return calendar.GetMeetingStatus(m.Events(), m.clock.Now())
+}
@@ -0,0 +1,155 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"next-meeting/calendar"
+	"next-meeting/clock"
+)
+
+func TestRefresh(t *testing.T) {
+	fixedNow := time.Date(2026, 1, 9, 14, 30, 0, 0, time.UTC)
+	events := []*calendar.MeetingInfo{
+		{Summary: "Current", Start: fixedNow.Add(-time.Minute), End: fixedNow.Add(time.Hour)},
+		{Summary: "Next", Start: fixedNow.Add(2 * time.Hour), End: fixedNow.Add(3 * time.Hour)},
+	}
+
+	// Events are served from a cache filled 5 minutes ago unless forced
+	cachedAt := fixedNow.Add(-5 * time.Minute)
+	var fetchErr error
+	var forced []bool
+	fetch := func(ctx context.Context, force bool) ([]*calendar.MeetingInfo, time.Time, error) {
+		forced = append(forced, force)
+		if fetchErr != nil {
+			return nil, time.Time{}, fetchErr
+		}
+		if force {
+			return events, fixedNow, nil
+		}
+		return events, cachedAt, nil
+	}
+
+	m := New(fetch, clock.Fixed(fixedNow), time.Minute)
+
+	if err := m.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	if len(forced) != 1 || forced[0] {
+		t.Errorf("expected 1 fetch that is not forced, got %v", forced)
+	}
+	if !m.FetchedAt().Equal(cachedAt) {
+		t.Errorf("expected FetchedAt %v, got %v", cachedAt, m.FetchedAt())
+	}
+
+	status := m.Status()
+	if status.CurrentMeeting == nil || status.CurrentMeeting.Summary != "Current" {
+		t.Errorf("expected current meeting 'Current', got %+v", status.CurrentMeeting)
+	}
+	if status.NextMeeting == nil || status.NextMeeting.Summary != "Next" {
+		t.Errorf("expected next meeting 'Next', got %+v", status.NextMeeting)
+	}
+
+	t.Run("forced refresh bypasses the cache", func(t *testing.T) {
+		if err := m.ForceRefresh(context.Background()); err != nil {
+			t.Fatalf("ForceRefresh failed: %v", err)
+		}
+		if len(forced) != 2 || !forced[1] {
+			t.Errorf("expected a forced fetch, got %v", forced)
+		}
+		if !m.FetchedAt().Equal(fixedNow) {
+			t.Errorf("expected FetchedAt %v, got %v", fixedNow, m.FetchedAt())
+		}
+	})
+
+	t.Run("failed refresh keeps previous events", func(t *testing.T) {
+		fetchErr = errors.New("offline")
+		if err := m.Refresh(context.Background()); err == nil {
+			t.Fatalf("expected Refresh to fail")
+		}
+		if m.Err() == nil {
+			t.Errorf("expected Err to report the failure")
+		}
+		if len(m.Events()) != 2 {
+			t.Errorf("expected previous events to be kept, got %d", len(m.Events()))
+		}
+	})
+
+	t.Run("successful refresh clears error", func(t *testing.T) {
+		fetchErr = nil
+		if err := m.Refresh(context.Background()); err != nil {
+			t.Fatalf("Refresh failed: %v", err)
+		}
+		if m.Err() != nil {
+			t.Errorf("expected no error, got %v", m.Err())
+		}
+	})
+}
+
+func TestRunStopsOnCancel(t *testing.T) {
+	fetch := func(ctx context.Context, force bool) ([]*calendar.MeetingInfo, time.Time, error) {
+		return nil, time.Now(), nil
+	}
+	m := New(fetch, clock.System(), time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		m.Run(ctx)
+		close(done)
+	}()
+
+	time.Sleep(5 * time.Millisecond)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not stop after cancel")
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	fail := false
+	fetch := func(ctx context.Context, force bool) ([]*calendar.MeetingInfo, time.Time, error) {
+		if fail {
+			return nil, time.Time{}, errors.New("offline")
+		}
+		return nil, time.Now(), nil
+	}
+	m := New(fetch, clock.System(), time.Minute)
+
+	updates, stop := m.Subscribe()
+
+	_ = m.Refresh(context.Background())
+	_ = m.Refresh(context.Background())
+	select {
+	case <-updates:
+	default:
+		t.Fatal("expected a signal after refresh")
+	}
+	select {
+	case <-updates:
+		t.Fatal("expected signals to be coalesced")
+	default:
+	}
+
+	fail = true
+	_ = m.Refresh(context.Background())
+	select {
+	case <-updates:
+		t.Fatal("expected no signal after a failed refresh")
+	default:
+	}
+
+	fail = false
+	stop()
+	_ = m.Refresh(context.Background())
+	select {
+	case <-updates:
+		t.Fatal("expected no signal after unsubscribing")
+	default:
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bufio"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"next-meeting/config"
+	"next-meeting/keyring"
+	"next-meeting/mqttpub"
+)
+
+// mqttOptions builds the MQTT publisher options from the config, reading the
+// password from the keyring if a username is set
+func mqttOptions(cfg *config.Config) (mqttpub.Options, error) {
+	opts := mqttpub.Options{
+		Broker:          cfg.MQTTBroker,
+		ClientID:        cfg.MQTTClientID,
+		Username:        cfg.MQTTUsername,
+		Topic:           cfg.MQTTTopic,
+		Discovery:       cfg.MQTTDiscovery,
+		DiscoveryPrefix: cfg.MQTTDiscoveryPrefix,
+	}
+	if opts.ClientID == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return opts, err
+		}
+		opts.ClientID = mqttpub.DefaultTopic + "-" + hostname
+	}
+
+	if opts.Username != "" {
+		password, err := keyring.LoadMQTTPassword()
+		if err != nil {
+			return opts, fmt.Errorf("no MQTT password, run \"next-meeting mqtt login\": %w", err)
+		}
+		opts.Password = password
+	}
+
+	if cfg.MQTTCAFile != "" {
+		pem, err := os.ReadFile(cfg.MQTTCAFile)
+		if err != nil {
+			return opts, fmt.Errorf("failed to read MQTT CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return opts, fmt.Errorf("no certificates found in %s", cfg.MQTTCAFile)
+		}
+		opts.TLS = &tls.Config{RootCAs: pool}
+	}
+	return opts, nil
+}
+
+// runMQTTCommand handles the "mqtt login" and "mqtt logout" subcommands
+func runMQTTCommand(args []string) {
+	if len(args) == 0 {
+		errorAndExit("%v\n", errors.New("usage: next-meeting mqtt login|logout"))
+	}
+
+	switch args[0] {
+	case "login":
+		fmt.Fprint(os.Stderr, "MQTT password: ")
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		password := strings.TrimRight(line, "\r\n")
+		if password == "" {
+			if err == nil {
+				err = errors.New("empty password")
+			}
+			errorAndExit("Error reading MQTT password: %v\n", err)
+		}
+		if err := keyring.SaveMQTTPassword(password); err != nil {
+			errorAndExit("Error saving MQTT password: %v\n", err)
+		}
+		fmt.Println("✓ MQTT password saved")
+	case "logout":
+		if err := keyring.DeleteMQTTPassword(); err != nil {
+			errorAndExit("Error clearing MQTT password: %v\n", err)
+		}
+		fmt.Println("✓ MQTT password cleared")
+	default:
+		errorAndExit("%v\n", fmt.Errorf("unknown mqtt command %q", args[0]))
+	}
+}
@@ -0,0 +1,296 @@
+package mqttpub
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"next-meeting/calendar"
+	"next-meeting/monitor"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+const (
+	// DefaultTopic is the topic prefix state is published under
+	DefaultTopic = "next-meeting"
+	// DefaultDiscoveryPrefix is the topic prefix Home Assistant watches for discovery
+	DefaultDiscoveryPrefix = "homeassistant"
+)
+
+// publishInterval is how often the publisher checks whether the state changed
+const publishInterval = time.Second
+
+// Timeouts for talking to the broker
+const (
+	connectTimeout = 10 * time.Second
+	connectRetry   = 30 * time.Second // How long to wait between attempts while the broker can't be reached
+	publishTimeout = 5 * time.Second
+	quiesce        = 250 // Milliseconds to wait for pending work on disconnect
+)
+
+// Availability payloads
+const (
+	online  = "online"
+	offline = "offline"
+)
+
+// State is the retained state published under the state topic
+type State struct {
+	InMeeting     bool   `json:"in_meeting"`
+	Current       string `json:"current"`
+	Next          string `json:"next"`
+	MinutesToNext *int   `json:"minutes_to_next"` // null without a next meeting
+	Conference    string `json:"conference"`      // Provider of the current meeting, or the next one when free
+}
+
+// NewState builds the published state for a meeting status
+func NewState(status *calendar.MeetingStatus, now time.Time) State {
+	var s State
+	if current := status.CurrentMeeting; current != nil {
+		s.InMeeting = true
+		s.Current = current.Summary
+		s.Conference = current.Conference
+	}
+	if next := status.NextMeeting; next != nil {
+		s.Next = next.Summary
+		minutes := int(math.Ceil(next.Start.Sub(now).Minutes()))
+		s.MinutesToNext = &minutes
+		if s.Conference == "" {
+			s.Conference = next.Conference
+		}
+	}
+	return s
+}
+
+// fields returns each field of the state with the payload published on its
+// own topic, so simple devices don't have to parse JSON
+func (s State) fields() map[string]string {
+	minutes := ""
+	if s.MinutesToNext != nil {
+		minutes = fmt.Sprint(*s.MinutesToNext)
+	}
+	return map[string]string{
+		"in_meeting":      fmt.Sprint(s.InMeeting),
+		"current":         s.Current,
+		"next":            s.Next,
+		"minutes_to_next": minutes,
+		"conference":      s.Conference,
+	}
+}
+
+// Options configure the publisher
+type Options struct {
+	Broker          string // Broker URL, e.g. tcp://localhost:1883 or ssl://broker:8883
+	ClientID        string
+	Username        string
+	Password        string
+	TLS             *tls.Config // Used for ssl:// brokers; nil uses the system roots
+	Topic           string      // Topic prefix, DefaultTopic if empty
+	Discovery       bool        // Publish Home Assistant discovery config
+	DiscoveryPrefix string      // DefaultDiscoveryPrefix if empty
+}
+
+// Publisher publishes the meeting status computed from the events kept by a monitor
+type Publisher struct {
+	mon      *monitor.Monitor
+	opts     Options
+	interval time.Duration
+	retry    time.Duration
+}
+
+// NewPublisher creates a publisher for mon
+func NewPublisher(mon *monitor.Monitor, opts Options) *Publisher {
+	if opts.Topic == "" {
+		opts.Topic = DefaultTopic
+	}
+	if opts.DiscoveryPrefix == "" {
+		opts.DiscoveryPrefix = DefaultDiscoveryPrefix
+	}
+	if opts.ClientID == "" {
+		opts.ClientID = DefaultTopic
+	}
+	return &Publisher{mon: mon, opts: opts, interval: publishInterval, retry: connectRetry}
+}
+
+// Serve connects to the broker and publishes the state whenever it changes,
+// until ctx is cancelled. Connecting is retried until the broker can be
+// reached, and the connection is re-established if it drops; the broker marks
+// the publisher offline while it is gone.
+func (p *Publisher) Serve(ctx context.Context) error {
+	connected := make(chan struct{}, 1)
+	opts := mqtt.NewClientOptions().
+		AddBroker(p.opts.Broker).
+		SetClientID(p.opts.ClientID).
+		SetUsername(p.opts.Username).
+		SetPassword(p.opts.Password).
+		SetTLSConfig(p.opts.TLS).
+		SetWill(p.topic("availability"), offline, 1, true).
+		SetConnectTimeout(connectTimeout).
+		SetConnectRetry(true).
+		SetConnectRetryInterval(p.retry).
+		SetAutoReconnect(true).
+		SetOnConnectHandler(func(mqtt.Client) {
+			select {
+			case connected <- struct{}{}:
+			default:
+			}
+		})
+
+	client := mqtt.NewClient(opts)
+	// Completes once connected, which the handler reports
+	client.Connect()
+	defer client.Disconnect(quiesce)
+
+	updates, unsubscribe := p.mon.Subscribe()
+	defer unsubscribe()
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	// Announcing happens on every connection, as the will replaced
+	// availability while disconnected, and is retried on failure
+	announce, online := false, false
+	var last *State
+	for {
+		if announce && p.announce(client) == nil {
+			announce, online, last = false, true, nil
+		}
+		if state, ok := p.state(); online && ok && (last == nil || !equal(*last, state)) {
+			// Failures are retried on the next tick
+			if err := p.publishState(client, state); err == nil {
+				last = &state
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if online {
+				_ = wait(client.Publish(p.topic("availability"), 1, true, offline), publishTimeout)
+			}
+			return nil
+		case <-connected:
+			announce = true
+		case <-updates:
+		case <-ticker.C:
+		}
+	}
+}
+
+// state returns the current state, or false if no events could be loaded
+func (p *Publisher) state() (State, bool) {
+	events := p.mon.Events()
+	if events == nil && p.mon.Err() != nil {
+		return State{}, false
+	}
+	now := p.mon.Now()
+	return NewState(calendar.GetMeetingStatus(events, now), now), true
+}
+
+// announce publishes discovery config, if enabled, and marks the publisher online
+func (p *Publisher) announce(client mqtt.Client) error {
+	if p.opts.Discovery {
+		for topic, config := range p.discovery() {
+			data, err := json.Marshal(config)
+			if err != nil {
+				return err
+			}
+			if err := wait(client.Publish(topic, 1, true, data), publishTimeout); err != nil {
+				return fmt.Errorf("publishing discovery config: %w", err)
+			}
+		}
+	}
+	if err := wait(client.Publish(p.topic("availability"), 1, true, online), publishTimeout); err != nil {
+		return fmt.Errorf("publishing availability: %w", err)
+	}
+	return nil
+}
+
+// publishState publishes the state as JSON and each field on its own topic
+func (p *Publisher) publishState(client mqtt.Client, state State) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := wait(client.Publish(p.topic("state"), 1, true, data), publishTimeout); err != nil {
+		return err
+	}
+	for field, payload := range state.fields() {
+		if err := wait(client.Publish(p.topic(field), 1, true, payload), publishTimeout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// topic returns the topic for name under the configured prefix
+func (p *Publisher) topic(name string) string {
+	return p.opts.Topic + "/" + name
+}
+
+// entity describes a Home Assistant entity backed by a field of the state
+type entity struct {
+	component string
+	field     string
+	name      string
+	template  string
+	unit      string
+}
+
+var entities = []entity{
+	{component: "binary_sensor", field: "in_meeting", name: "In meeting", template: "{{ 'ON' if value_json.in_meeting else 'OFF' }}"},
+	{component: "sensor", field: "current", name: "Current meeting", template: "{{ value_json.current }}"},
+	{component: "sensor", field: "next", name: "Next meeting", template: "{{ value_json.next }}"},
+	{component: "sensor", field: "minutes_to_next", name: "Minutes to next meeting", template: "{{ value_json.minutes_to_next }}", unit: "min"},
+	{component: "sensor", field: "conference", name: "Conference provider", template: "{{ value_json.conference }}"},
+}
+
+// discovery returns the Home Assistant discovery config for each entity by topic
+func (p *Publisher) discovery() map[string]map[string]any {
+	device := map[string]any{
+		"identifiers": []string{p.opts.ClientID},
+		"name":        "next-meeting",
+	}
+	configs := make(map[string]map[string]any, len(entities))
+	for _, e := range entities {
+		id := p.opts.ClientID + "_" + e.field
+		config := map[string]any{
+			"name":               e.name,
+			"unique_id":          id,
+			"object_id":          id,
+			"state_topic":        p.topic("state"),
+			"value_template":     e.template,
+			"availability_topic": p.topic("availability"),
+			"device":             device,
+		}
+		if e.unit != "" {
+			config["unit_of_measurement"] = e.unit
+		}
+		topic := fmt.Sprintf("%s/%s/%s/%s/config", p.opts.DiscoveryPrefix, e.component, p.opts.ClientID, e.field)
+		configs[topic] = config
+	}
+	return configs
+}
+
+// equal reports whether two states would be published the same
+func equal(a, b State) bool {
+	if (a.MinutesToNext == nil) != (b.MinutesToNext == nil) {
+		return false
+	}
+	if a.MinutesToNext != nil && *a.MinutesToNext != *b.MinutesToNext {
+		return false
+	}
+	a.MinutesToNext, b.MinutesToNext = nil, nil
+	return a == b
+}
+
+// wait waits for token to complete and returns its error
+func wait(token mqtt.Token, timeout time.Duration) error {
+	if !token.WaitTimeout(timeout) {
+		return errors.New("timed out")
+	}
+	return token.Error()
+}
@@ -0,0 +1,315 @@
+package mqttpub
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"next-meeting/calendar"
+	"next-meeting/clock"
+	"next-meeting/monitor"
+)
+
+// fakeBroker is just enough of an MQTT 3.1.1 broker to accept one client and
+// keep the retained messages it publishes
+type fakeBroker struct {
+	l net.Listener
+
+	mu       sync.Mutex
+	username string
+	password string
+	will     string
+	retained map[string]string
+	changed  chan struct{}
+}
+
+// newFakeBroker starts a broker listening on addr
+func newFakeBroker(t *testing.T, addr string) *fakeBroker {
+	t.Helper()
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &fakeBroker{l: l, retained: map[string]string{}, changed: make(chan struct{}, 1)}
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go b.serve(conn)
+		}
+	}()
+	return b
+}
+
+func (b *fakeBroker) URL() string {
+	return "tcp://" + b.l.Addr().String()
+}
+
+func (b *fakeBroker) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		header, err := r.ReadByte()
+		if err != nil {
+			return
+		}
+		length, err := binary.ReadUvarint(r)
+		if err != nil {
+			return
+		}
+		body := make([]byte, length)
+		if _, err := io.ReadFull(r, body); err != nil {
+			return
+		}
+
+		switch header >> 4 {
+		case 1: // CONNECT
+			b.connect(body)
+			_, _ = conn.Write([]byte{0x20, 2, 0, 0})
+		case 3: // PUBLISH
+			qos := header >> 1 & 3
+			topic, rest := readString(body)
+			if qos > 0 {
+				_, _ = conn.Write([]byte{0x40, 2, rest[0], rest[1]})
+				rest = rest[2:]
+			}
+			if header&1 == 1 {
+				b.retain(topic, string(rest))
+			}
+		case 12: // PINGREQ
+			_, _ = conn.Write([]byte{0xd0, 0})
+		case 14: // DISCONNECT
+			return
+		}
+	}
+}
+
+func (b *fakeBroker) connect(body []byte) {
+	_, rest := readString(body) // Protocol name
+	flags := rest[1]
+	rest = rest[4:]            // Level, flags and keep alive
+	_, rest = readString(rest) // Client ID
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if flags&0x04 != 0 {
+		var topic, payload string
+		topic, rest = readString(rest)
+		payload, rest = readString(rest)
+		b.will = topic + "=" + payload
+	}
+	if flags&0x80 != 0 {
+		b.username, rest = readString(rest)
+	}
+	if flags&0x40 != 0 {
+		b.password, _ = readString(rest)
+	}
+}
+
+func (b *fakeBroker) retain(topic, payload string) {
+	b.mu.Lock()
+	b.retained[topic] = payload
+	b.mu.Unlock()
+	select {
+	case b.changed <- struct{}{}:
+	default:
+	}
+}
+
+// waitFor waits until the retained message on topic satisfies ok
+func (b *fakeBroker) waitFor(t *testing.T, topic string, ok func(string) bool) string {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		b.mu.Lock()
+		payload, found := b.retained[topic]
+		b.mu.Unlock()
+		if found && ok(payload) {
+			return payload
+		}
+		select {
+		case <-b.changed:
+		case <-timeout:
+			t.Fatalf("timed out waiting for %s, last payload %q", topic, payload)
+		}
+	}
+}
+
+func readString(data []byte) (string, []byte) {
+	n := int(binary.BigEndian.Uint16(data))
+	return string(data[2 : 2+n]), data[2+n:]
+}
+
+func anyPayload(string) bool { return true }
+
+func TestNewState(t *testing.T) {
+	now := time.Date(2026, 1, 9, 9, 0, 0, 0, time.UTC)
+	current := &calendar.MeetingInfo{Summary: "Standup", Start: now.Add(-5 * time.Minute), End: now.Add(10 * time.Minute), Conference: "Google Meet"}
+	next := &calendar.MeetingInfo{Summary: "Review", Start: now.Add(90*time.Second + 30*time.Minute), End: now.Add(time.Hour), Conference: "Zoom"}
+
+	tests := []struct {
+		name   string
+		status *calendar.MeetingStatus
+		want   string
+	}{
+		{
+			name:   "free day",
+			status: &calendar.MeetingStatus{},
+			want:   `{"in_meeting":false,"current":"","next":"","minutes_to_next":null,"conference":""}`,
+		},
+		{
+			name:   "in meeting",
+			status: &calendar.MeetingStatus{CurrentMeeting: current, NextMeeting: next},
+			want:   `{"in_meeting":true,"current":"Standup","next":"Review","minutes_to_next":32,"conference":"Google Meet"}`,
+		},
+		{
+			name:   "free before next",
+			status: &calendar.MeetingStatus{NextMeeting: next},
+			want:   `{"in_meeting":false,"current":"","next":"Review","minutes_to_next":32,"conference":"Zoom"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(NewState(tt.status, now))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, data)
+			}
+		})
+	}
+}
+
+func TestPublisher(t *testing.T) {
+	broker := newFakeBroker(t, "127.0.0.1:0")
+	now := time.Date(2026, 1, 9, 9, 0, 0, 0, time.UTC)
+	events := []*calendar.MeetingInfo{
+		{Summary: "Standup", Start: now.Add(-time.Minute), End: now.Add(14 * time.Minute), Conference: "Google Meet"},
+	}
+	fetch := func(ctx context.Context, force bool) ([]*calendar.MeetingInfo, time.Time, error) {
+		return events, now, nil
+	}
+	mon := monitor.New(fetch, clock.Fixed(now), time.Minute)
+	if err := mon.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	pub := NewPublisher(mon, Options{
+		Broker:    broker.URL(),
+		ClientID:  "laptop",
+		Username:  "user",
+		Password:  "secret",
+		Topic:     "office/meeting",
+		Discovery: true,
+	})
+	pub.interval = 10 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- pub.Serve(ctx) }()
+
+	broker.waitFor(t, "office/meeting/availability", func(p string) bool { return p == "online" })
+	state := broker.waitFor(t, "office/meeting/state", anyPayload)
+	if want := `{"in_meeting":true,"current":"Standup","next":"","minutes_to_next":null,"conference":"Google Meet"}`; state != want {
+		t.Errorf("expected state %s, got %s", want, state)
+	}
+	broker.waitFor(t, "office/meeting/in_meeting", func(p string) bool { return p == "true" })
+	broker.waitFor(t, "office/meeting/minutes_to_next", func(p string) bool { return p == "" })
+
+	data := broker.waitFor(t, "homeassistant/binary_sensor/laptop/in_meeting/config", anyPayload)
+	var config map[string]any
+	if err := json.Unmarshal([]byte(data), &config); err != nil {
+		t.Fatalf("invalid discovery config: %v", err)
+	}
+	if config["state_topic"] != "office/meeting/state" || config["unique_id"] != "laptop_in_meeting" || config["availability_topic"] != "office/meeting/availability" {
+		t.Errorf("unexpected discovery config: %s", data)
+	}
+	broker.waitFor(t, "homeassistant/sensor/laptop/minutes_to_next/config", anyPayload)
+
+	broker.mu.Lock()
+	if broker.username != "user" || broker.password != "secret" {
+		t.Errorf("unexpected credentials %q/%q", broker.username, broker.password)
+	}
+	if broker.will != "office/meeting/availability=offline" {
+		t.Errorf("unexpected will %q", broker.will)
+	}
+	broker.mu.Unlock()
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Serve failed: %v", err)
+	}
+	broker.waitFor(t, "office/meeting/availability", func(p string) bool { return p == "offline" })
+}
+
+func TestPublisherWaitsForBroker(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	now := time.Date(2026, 1, 9, 9, 0, 0, 0, time.UTC)
+	mon := monitor.New(func(ctx context.Context, force bool) ([]*calendar.MeetingInfo, time.Time, error) {
+		return nil, now, nil
+	}, clock.Fixed(now), time.Minute)
+	if err := mon.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	pub := NewPublisher(mon, Options{Broker: "tcp://" + addr})
+	pub.interval = 10 * time.Millisecond
+	pub.retry = 10 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- pub.Serve(ctx) }()
+
+	// The broker is unreachable at first, which must not stop the publisher
+	select {
+	case err := <-done:
+		t.Fatalf("Serve returned while the broker was unreachable: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	broker := newFakeBroker(t, addr)
+	broker.waitFor(t, "next-meeting/availability", func(p string) bool { return p == "online" })
+	broker.waitFor(t, "next-meeting/in_meeting", func(p string) bool { return p == "false" })
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Serve failed: %v", err)
+	}
+}
+
+func TestPublisherStopsWithoutBroker(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	mon := monitor.New(func(ctx context.Context, force bool) ([]*calendar.MeetingInfo, time.Time, error) {
+		return nil, time.Now(), nil
+	}, clock.System(), time.Minute)
+	pub := NewPublisher(mon, Options{Broker: "tcp://" + addr})
+	pub.retry = 10 * time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := pub.Serve(ctx); err != nil {
+		t.Errorf("expected Serve to stop cleanly when cancelled, got %v", err)
+	}
+}
@@ -11,15 +11,27 @@ import (
 	"image/png"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
 	"next-meeting/calendar"
+	"next-meeting/filelock"
+	"next-meeting/secure"
 
 	"github.com/gen2brain/beeep"
 )
 
 const notifyDir = "next-meeting-notify"
 
+// encrypt controls whether notification markers are encrypted at rest
+var encrypt bool
+
+// SetEncryption enables or disables encryption of notification markers
+func SetEncryption(enabled bool) {
+	encrypt = enabled
+}
+
 //go:embed icon.png
 var defaultIconBytes []byte
 
@@ -64,33 +76,117 @@ func ensureDefaultIcon() string {
 	return p
 }
 
-func getNotificationID(meeting *calendar.MeetingInfo) string {
-	data := fmt.Sprintf("%s|%s|%s", meeting.Summary, meeting.Start.Format(time.RFC3339), meeting.End.Format(time.RFC3339))
+func getNotificationID(meeting *calendar.MeetingInfo, threshold time.Duration) string {
+	data := fmt.Sprintf("%s|%s|%s|%s", meeting.Summary, meeting.Start.Format(time.RFC3339), meeting.End.Format(time.RFC3339), threshold)
 	hash := sha256.Sum256([]byte(data))
 	return hex.EncodeToString(hash[:8])
 }
 
-func getNotifyFilePath(meeting *calendar.MeetingInfo) string {
-	return filepath.Join(getNotifyDir(), getNotificationID(meeting))
+// getConflictsNotificationID identifies the double-booking notification of a day
+func getConflictsNotificationID(day time.Time) string {
+	hash := sha256.Sum256([]byte("conflicts|" + day.Format(time.DateOnly)))
+	return hex.EncodeToString(hash[:8])
+}
+
+func getNotifyFilePath(meeting *calendar.MeetingInfo, threshold time.Duration) string {
+	return filepath.Join(getNotifyDir(), getNotificationID(meeting, threshold))
 }
 
-func HasBeenNotified(meeting *calendar.MeetingInfo) bool {
-	_, err := os.Stat(getNotifyFilePath(meeting))
+// HasBeenNotified reports whether the notification threshold before meeting was sent
+func HasBeenNotified(meeting *calendar.MeetingInfo, threshold time.Duration) bool {
+	_, err := os.Stat(getNotifyFilePath(meeting, threshold))
 	return err == nil
 }
 
-func MarkNotified(meeting *calendar.MeetingInfo) error {
+// MarkNotified records that the notification threshold before meeting was sent
+func MarkNotified(meeting *calendar.MeetingInfo, threshold time.Duration) error {
+	return writeMarker(getNotificationID(meeting, threshold), meeting.Summary)
+}
+
+// Lock takes the lock that makes checking, sending and marking a notification
+// one step across processes, and returns a function that releases it
+func Lock() (unlock func(), err error) {
+	return filelock.Lock(getNotifyDir())
+}
+
+// writeMarker records that the notification with the given ID was sent
+func writeMarker(id, content string) error {
 	dir := getNotifyDir()
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return fmt.Errorf("failed to create notify directory: %w", err)
 	}
 
-	filePath := getNotifyFilePath(meeting)
-	return os.WriteFile(filePath, []byte(meeting.Summary), 0600)
+	data := []byte(content)
+	if encrypt {
+		sealed, err := secure.Seal(data)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt notification marker: %w", err)
+		}
+		data = sealed
+	}
+
+	return os.WriteFile(filepath.Join(dir, id), data, 0600)
+}
+
+// Alert is a notification due for a meeting
+type Alert struct {
+	Meeting   *calendar.MeetingInfo
+	Threshold time.Duration // The threshold that fired
+	Last      bool          // Whether it is the smallest threshold, the final reminder to join
+}
+
+// RemindersKeyword in a threshold list stands for each meeting's own reminders
+const RemindersKeyword = "reminders"
+
+// Thresholds are how long before meetings to notify
+type Thresholds struct {
+	Fixed     []time.Duration // Apply to every meeting, largest first
+	Reminders bool            // Also notify at each meeting's own reminders
+}
+
+// ParseThresholds parses a comma-separated list of durations such as
+// "10m,1m", which may include RemindersKeyword
+func ParseThresholds(s string) (Thresholds, error) {
+	var thresholds Thresholds
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		if field == RemindersKeyword {
+			thresholds.Reminders = true
+			continue
+		}
+		threshold, err := time.ParseDuration(field)
+		if err != nil {
+			return Thresholds{}, err
+		}
+		if threshold <= 0 {
+			return Thresholds{}, fmt.Errorf("threshold must be positive: %s", field)
+		}
+		thresholds.Fixed = append(thresholds.Fixed, threshold)
+	}
+	thresholds.Fixed = sortThresholds(thresholds.Fixed)
+	return thresholds, nil
+}
+
+// For returns the thresholds that apply to meeting, largest first
+func (t Thresholds) For(meeting *calendar.MeetingInfo) []time.Duration {
+	if !t.Reminders {
+		return t.Fixed
+	}
+	merged := slices.Clone(meeting.Reminders)
+	return sortThresholds(append(merged, t.Fixed...))
 }
 
-func SendNotification(meeting *calendar.MeetingInfo, startsIn time.Duration) error {
-	body := "Upcoming meeting starting soon"
+// sortThresholds sorts thresholds largest first and removes duplicates
+func sortThresholds(thresholds []time.Duration) []time.Duration {
+	slices.Sort(thresholds)
+	slices.Reverse(thresholds)
+	return slices.Compact(thresholds)
+}
+
+// SendNotification announces a meeting starting in startsIn. The final
+// reminder asks to join; earlier ones are a heads-up.
+func SendNotification(alert Alert, startsIn time.Duration) error {
+	meeting := alert.Meeting
 	var title string
 	if startsIn < time.Minute {
 		title = fmt.Sprintf("🕐 %s — starting now", meeting.Summary)
@@ -98,10 +194,45 @@ func SendNotification(meeting *calendar.MeetingInfo, startsIn time.Duration) err
 		title = fmt.Sprintf("🕐 %s — in %s", meeting.Summary, calendar.FormatDuration(startsIn))
 	}
 
+	body := fmt.Sprintf("Heads-up: starts at %s", meeting.Start.Format("15:04"))
+	if alert.Last {
+		body = "Time to join"
+	}
 	if meeting.HangoutLink != "" {
 		body += fmt.Sprintf(": %s", meeting.HangoutLink)
 	}
 
+	return SendMessage(title, body)
+}
+
+// SendGroupNotification announces several meetings starting at the same
+// time, in startsIn, with one notification
+func SendGroupNotification(alerts []Alert, startsIn time.Duration) error {
+	if len(alerts) == 1 {
+		return SendNotification(alerts[0], startsIn)
+	}
+
+	at := alerts[0].Meeting.Start.Format("15:04")
+	var title string
+	if startsIn < time.Minute {
+		title = fmt.Sprintf("🕐 %d meetings at %s — starting now", len(alerts), at)
+	} else {
+		title = fmt.Sprintf("🕐 %d meetings at %s — in %s", len(alerts), at, calendar.FormatDuration(startsIn))
+	}
+
+	var lines []string
+	for _, alert := range alerts {
+		line := alert.Meeting.Summary
+		if alert.Meeting.HangoutLink != "" {
+			line += fmt.Sprintf(": %s", alert.Meeting.HangoutLink)
+		}
+		lines = append(lines, line)
+	}
+	return SendMessage(title, strings.Join(lines, "\n"))
+}
+
+// SendMessage shows a notification with the given title and body
+func SendMessage(title, body string) error {
 	icon := ensureDefaultIcon()
 	if err := beeep.Notify(title, body, icon); err != nil {
 		return fmt.Errorf("failed to send notification: %w", err)
@@ -109,39 +240,168 @@ func SendNotification(meeting *calendar.MeetingInfo, startsIn time.Duration) err
 	return nil
 }
 
-func ShouldNotify(status *calendar.MeetingStatus, threshold time.Duration) *calendar.MeetingInfo {
-	if status.NextMeeting == nil {
+// StartGrace is how long after a meeting started a reminder at its start time
+// is still sent, since runs rarely happen at the exact second
+const StartGrace = 2 * time.Minute
+
+// StillDue reports whether a meeting starting in startsIn can still be
+// notified at threshold: before it starts, or within StartGrace after it
+// started for a reminder at the start time
+func StillDue(startsIn, threshold time.Duration) bool {
+	return startsIn > 0 || threshold == 0 && startsIn > -StartGrace
+}
+
+// ShouldNotify returns the alerts due for meetings that have not started yet,
+// or just started for reminders at the start time, earliest first. Meetings
+// starting at the same time each get their own alert.
+func ShouldNotify(status *calendar.MeetingStatus, t Thresholds, now time.Time) []Alert {
+	var started []*calendar.MeetingInfo
+	for _, meeting := range append([]*calendar.MeetingInfo{status.CurrentMeeting}, status.Parallel...) {
+		if meeting != nil && !meeting.Start.After(now) && !slices.Contains(started, meeting) {
+			started = append(started, meeting)
+		}
+	}
+	slices.SortStableFunc(started, func(a, b *calendar.MeetingInfo) int {
+		return a.Start.Compare(b.Start)
+	})
+
+	var alerts []Alert
+	for _, meeting := range append(started, status.Upcoming...) {
+		if alert := shouldNotify(meeting, t.For(meeting), now); alert != nil {
+			alerts = append(alerts, *alert)
+		}
+	}
+	return alerts
+}
+
+// shouldNotify returns the alert due for meeting, or nil if there is none.
+// Only the smallest threshold the meeting is within counts, so larger ones
+// that were missed, e.g. while the computer was asleep, don't fire late.
+func shouldNotify(meeting *calendar.MeetingInfo, thresholds []time.Duration, now time.Time) *Alert {
+	startsIn := meeting.Start.Sub(now)
+	if len(thresholds) == 0 || !StillDue(startsIn, thresholds[len(thresholds)-1]) {
 		return nil
 	}
 
-	startsIn := time.Until(status.NextMeeting.Start)
-	if startsIn <= 0 {
+	i := -1
+	for j, threshold := range thresholds {
+		if startsIn <= threshold {
+			i = j
+		}
+	}
+	if i < 0 {
 		return nil
 	}
 
-	if startsIn > threshold {
+	if HasBeenNotified(meeting, thresholds[i]) {
 		return nil
 	}
 
-	if HasBeenNotified(status.NextMeeting) {
+	return &Alert{Meeting: meeting, Threshold: thresholds[i], Last: i == len(thresholds)-1}
+}
+
+// GroupByStart groups alerts for meetings starting at the same time, so they
+// can be announced together. The order of alerts is kept.
+func GroupByStart(alerts []Alert) [][]Alert {
+	var groups [][]Alert
+	for _, alert := range alerts {
+		i := slices.IndexFunc(groups, func(group []Alert) bool {
+			return group[0].Meeting.Start.Equal(alert.Meeting.Start)
+		})
+		if i < 0 {
+			groups = append(groups, []Alert{alert})
+			continue
+		}
+		groups[i] = append(groups[i], alert)
+	}
+	return groups
+}
+
+// ShouldNotifyConflicts returns the double-bookings of the day that have not
+// ended yet, unless they were already announced that day
+func ShouldNotifyConflicts(events []*calendar.MeetingInfo, now time.Time) []calendar.Conflict {
+	if _, err := os.Stat(filepath.Join(getNotifyDir(), getConflictsNotificationID(now))); err == nil {
 		return nil
 	}
 
-	return status.NextMeeting
+	var conflicts []calendar.Conflict
+	for _, conflict := range calendar.ConflictPairs(events) {
+		if conflict.Overlap().End.After(now) {
+			conflicts = append(conflicts, conflict)
+		}
+	}
+	return conflicts
+}
+
+// MarkConflictsNotified records that the double-bookings of the day were announced
+func MarkConflictsNotified(now time.Time) error {
+	return writeMarker(getConflictsNotificationID(now), now.Format(time.DateOnly))
+}
+
+// SendConflictNotification announces the double-bookings of the day
+func SendConflictNotification(conflicts []calendar.Conflict) error {
+	title := "⚠ Double-booked today"
+	if len(conflicts) > 1 {
+		title = fmt.Sprintf("⚠ %d double-bookings today", len(conflicts))
+	}
+
+	var lines []string
+	for _, conflict := range conflicts {
+		overlap := conflict.Overlap()
+		lines = append(lines, fmt.Sprintf("%s–%s %s and %s",
+			overlap.Start.Format("15:04"), overlap.End.Format("15:04"), conflict.A.Summary, conflict.B.Summary))
+	}
+	return SendMessage(title, strings.Join(lines, "\n"))
+}
+
+// getChainNotificationID identifies the warning about a chain of meetings
+func getChainNotificationID(chain *calendar.Chain) string {
+	data := fmt.Sprintf("chain|%s|%s", chain.Start.Format(time.RFC3339), chain.End.Format(time.RFC3339))
+	hash := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(hash[:8])
+}
+
+// ShouldNotifyChain reports whether to warn about a chain of back-to-back
+// meetings that starts within threshold and hasn't been warned about yet
+func ShouldNotifyChain(chain *calendar.Chain, threshold time.Duration, now time.Time) bool {
+	if chain == nil {
+		return false
+	}
+
+	startsIn := chain.Start.Sub(now)
+	if startsIn <= 0 || startsIn > threshold {
+		return false
+	}
+
+	_, err := os.Stat(filepath.Join(getNotifyDir(), getChainNotificationID(chain)))
+	return err != nil
+}
+
+// MarkChainNotified records that the warning about a chain was sent
+func MarkChainNotified(chain *calendar.Chain) error {
+	return writeMarker(getChainNotificationID(chain), chain.Start.Format(time.RFC3339))
+}
+
+// SendChainNotification warns that a chain of back-to-back meetings is about to start
+func SendChainNotification(chain *calendar.Chain, startsIn time.Duration) error {
+	title := fmt.Sprintf("⚠ %s back-to-back in %s", calendar.FormatDuration(chain.Duration()), calendar.FormatDuration(startsIn))
+	body := fmt.Sprintf("%d meetings from %s until %s without a break",
+		len(chain.Meetings), chain.Start.Format("15:04"), chain.End.Format("15:04"))
+	return SendMessage(title, body)
 }
 
 func Clear() error {
 	return os.RemoveAll(getNotifyDir())
 }
 
-func CleanOldNotifications() {
+func CleanOldNotifications(now time.Time) {
 	dir := getNotifyDir()
 	entries, err := os.ReadDir(dir)
 	if err != nil {
 		return
 	}
 
-	cutoff := time.Now().Add(-24 * time.Hour)
+	cutoff := now.Add(-24 * time.Hour)
 	for _, entry := range entries {
 		info, err := entry.Info()
 		if err != nil {
@@ -3,9 +3,14 @@ package notify
 import (
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/zalando/go-keyring"
+
 	"next-meeting/calendar"
 )
 
@@ -19,51 +24,320 @@ func TestMarkAndHasBeenNotified(t *testing.T) {
 		End:     time.Now().Add(2 * time.Hour),
 	}
 
-	if HasBeenNotified(m) {
+	if HasBeenNotified(m, 10*time.Minute) {
 		t.Fatalf("expected not notified initially")
 	}
 
-	if err := MarkNotified(m); err != nil {
+	if err := MarkNotified(m, 10*time.Minute); err != nil {
 		t.Fatalf("MarkNotified failed: %v", err)
 	}
 
-	if !HasBeenNotified(m) {
+	if !HasBeenNotified(m, 10*time.Minute) {
 		t.Fatalf("expected notified after MarkNotified")
 	}
+	if HasBeenNotified(m, time.Minute) {
+		t.Fatalf("expected other thresholds not to be notified")
+	}
 
-	if _, err := os.Stat(getNotifyFilePath(m)); err != nil {
+	if _, err := os.Stat(getNotifyFilePath(m, 10*time.Minute)); err != nil {
 		t.Fatalf("expected notify file to exist: %v", err)
 	}
 }
 
+func TestLockSendsOnce(t *testing.T) {
+	_ = Clear()
+	defer Clear()
+
+	m := &calendar.MeetingInfo{
+		Summary: "Test Meeting",
+		Start:   time.Now().Add(time.Hour),
+		End:     time.Now().Add(2 * time.Hour),
+	}
+
+	// Each goroutine checks and marks like a separate process would
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	sent := 0
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock, err := Lock()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer unlock()
+			if HasBeenNotified(m, 10*time.Minute) {
+				return
+			}
+			mu.Lock()
+			sent++
+			mu.Unlock()
+			time.Sleep(time.Millisecond)
+			if err := MarkNotified(m, 10*time.Minute); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if sent != 1 {
+		t.Errorf("expected 1 notification, got %d", sent)
+	}
+}
+
 func TestShouldNotifyBehavior(t *testing.T) {
 	_ = Clear()
 	defer Clear()
 
-	now := time.Now()
+	now := time.Date(2026, 1, 9, 13, 59, 0, 0, time.UTC)
 	m := &calendar.MeetingInfo{
 		Summary: "Soon Meeting",
 		Start:   now.Add(30 * time.Second),
 		End:     now.Add(90 * time.Second),
 	}
-	status := &calendar.MeetingStatus{NextMeeting: m}
+	status := calendar.GetMeetingStatus([]*calendar.MeetingInfo{m}, now)
 
-	if ShouldNotify(status, 1*time.Minute) == nil {
+	oneMinute := Thresholds{Fixed: []time.Duration{time.Minute}}
+	if len(ShouldNotify(status, oneMinute, now)) != 1 {
 		t.Fatalf("expected ShouldNotify to return meeting for 1m threshold")
 	}
 
-	if ShouldNotify(status, 10*time.Second) != nil {
+	if len(ShouldNotify(status, Thresholds{Fixed: []time.Duration{10 * time.Second}}, now)) != 0 {
 		t.Fatalf("expected ShouldNotify to return nil for 10s threshold")
 	}
 
-	if err := MarkNotified(m); err != nil {
+	if err := MarkNotified(m, time.Minute); err != nil {
 		t.Fatalf("MarkNotified failed: %v", err)
 	}
-	if ShouldNotify(status, 1*time.Minute) != nil {
+	if len(ShouldNotify(status, oneMinute, now)) != 0 {
 		t.Fatalf("expected ShouldNotify to return nil after marking notified")
 	}
 }
 
+func TestShouldNotifyThresholdsAcrossRuns(t *testing.T) {
+	_ = Clear()
+	defer Clear()
+
+	start := time.Date(2026, 1, 9, 14, 0, 0, 0, time.UTC)
+	m := &calendar.MeetingInfo{Summary: "Review", Start: start, End: start.Add(time.Hour)}
+	thresholds := Thresholds{Fixed: []time.Duration{10 * time.Minute, time.Minute}}
+
+	// Run every 20 seconds, twice each time, from 15 minutes before the
+	// meeting until it starts
+	fired := map[time.Duration]int{}
+	var last []bool
+	for now := start.Add(-15 * time.Minute); now.Before(start.Add(time.Minute)); now = now.Add(20 * time.Second) {
+		status := calendar.GetMeetingStatus([]*calendar.MeetingInfo{m}, now)
+		for range 2 {
+			for _, alert := range ShouldNotify(status, thresholds, now) {
+				if startsIn := m.Start.Sub(now); startsIn > alert.Threshold {
+					t.Errorf("%s threshold fired %s before the meeting", alert.Threshold, startsIn)
+				}
+				fired[alert.Threshold]++
+				last = append(last, alert.Last)
+				if err := MarkNotified(alert.Meeting, alert.Threshold); err != nil {
+					t.Fatalf("MarkNotified failed: %v", err)
+				}
+			}
+		}
+	}
+
+	for _, threshold := range thresholds.Fixed {
+		if fired[threshold] != 1 {
+			t.Errorf("expected the %s threshold to fire once, fired %d times", threshold, fired[threshold])
+		}
+	}
+	if len(last) != 2 || last[0] || !last[1] {
+		t.Errorf("expected only the 1m alert to be the last, got %v", last)
+	}
+}
+
+func TestShouldNotifySkipsMissedThresholds(t *testing.T) {
+	_ = Clear()
+	defer Clear()
+
+	now := time.Date(2026, 1, 9, 13, 59, 30, 0, time.UTC)
+	m := &calendar.MeetingInfo{Summary: "Review", Start: now.Add(30 * time.Second), End: now.Add(time.Hour)}
+	status := calendar.GetMeetingStatus([]*calendar.MeetingInfo{m}, now)
+	thresholds := Thresholds{Fixed: []time.Duration{10 * time.Minute, 5 * time.Minute, time.Minute}}
+
+	// Waking up 30 seconds before the meeting only sends the final reminder
+	alerts := ShouldNotify(status, thresholds, now)
+	if len(alerts) != 1 || alerts[0].Threshold != time.Minute || !alerts[0].Last {
+		t.Fatalf("expected the 1m alert, got %+v", alerts)
+	}
+	if err := MarkNotified(m, alerts[0].Threshold); err != nil {
+		t.Fatalf("MarkNotified failed: %v", err)
+	}
+	if alerts := ShouldNotify(status, thresholds, now.Add(10*time.Second)); len(alerts) != 0 {
+		t.Errorf("expected missed thresholds not to fire, got %+v", alerts)
+	}
+}
+
+func TestShouldNotifySimultaneousStarts(t *testing.T) {
+	_ = Clear()
+	defer Clear()
+
+	start := time.Date(2026, 1, 9, 14, 0, 0, 0, time.UTC)
+	real := &calendar.MeetingInfo{Summary: "Planning", Start: start, End: start.Add(time.Hour)}
+	overlapping := &calendar.MeetingInfo{Summary: "All hands", Start: start, End: start.Add(30 * time.Minute)}
+	later := &calendar.MeetingInfo{Summary: "Review", Start: start.Add(2 * time.Minute), End: start.Add(time.Hour)}
+	events := []*calendar.MeetingInfo{real, overlapping, later}
+	thresholds := Thresholds{Fixed: []time.Duration{10 * time.Minute}}
+
+	now := start.Add(-4 * time.Minute)
+	alerts := ShouldNotify(calendar.GetMeetingStatus(events, now), thresholds, now)
+	if len(alerts) != 3 {
+		t.Fatalf("expected an alert for each meeting, got %+v", alerts)
+	}
+
+	groups := GroupByStart(alerts)
+	if len(groups) != 2 || len(groups[0]) != 2 || len(groups[1]) != 1 || groups[1][0].Meeting != later {
+		t.Fatalf("expected the 14:00 meetings to be grouped, got %+v", groups)
+	}
+	for _, alert := range groups[0] {
+		if !alert.Meeting.Start.Equal(start) {
+			t.Errorf("unexpected meeting in the 14:00 group: %s", alert.Meeting.Summary)
+		}
+	}
+
+	// Only one of the simultaneous meetings was announced, e.g. by an older
+	// version; the other one is still due
+	if err := MarkNotified(real, 10*time.Minute); err != nil {
+		t.Fatalf("MarkNotified failed: %v", err)
+	}
+	alerts = ShouldNotify(calendar.GetMeetingStatus(events, now), thresholds, now)
+	if len(alerts) != 2 || alerts[0].Meeting != overlapping || alerts[1].Meeting != later {
+		t.Fatalf("expected the other meetings to still be due, got %+v", alerts)
+	}
+	for _, alert := range alerts {
+		if err := MarkNotified(alert.Meeting, alert.Threshold); err != nil {
+			t.Fatalf("MarkNotified failed: %v", err)
+		}
+	}
+	if alerts := ShouldNotify(calendar.GetMeetingStatus(events, now.Add(time.Minute)), thresholds, now.Add(time.Minute)); len(alerts) != 0 {
+		t.Errorf("expected each meeting to be announced once, got %+v", alerts)
+	}
+
+	// Once they started, the next meeting is still announced
+	if err := Clear(); err != nil {
+		t.Fatal(err)
+	}
+	afterStart := start.Add(time.Minute)
+	alerts = ShouldNotify(calendar.GetMeetingStatus(events, afterStart), thresholds, afterStart)
+	if len(alerts) != 1 || alerts[0].Meeting != later {
+		t.Errorf("expected only Review after the 14:00 meetings started, got %+v", alerts)
+	}
+}
+
+func TestParseThresholds(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Thresholds
+		wantErr bool
+	}{
+		{input: "5m", want: Thresholds{Fixed: []time.Duration{5 * time.Minute}}},
+		{input: "1m,10m", want: Thresholds{Fixed: []time.Duration{10 * time.Minute, time.Minute}}},
+		{input: "10m, 1m, 10m", want: Thresholds{Fixed: []time.Duration{10 * time.Minute, time.Minute}}},
+		{input: "reminders", want: Thresholds{Reminders: true}},
+		{input: "reminders,1m", want: Thresholds{Fixed: []time.Duration{time.Minute}, Reminders: true}},
+		{input: "10m,soon", wantErr: true},
+		{input: "0s", wantErr: true},
+		{input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseThresholds(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseThresholds(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if !slices.Equal(got.Fixed, tt.want.Fixed) || got.Reminders != tt.want.Reminders {
+			t.Errorf("ParseThresholds(%q) = %+v, want %+v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestThresholdsFor(t *testing.T) {
+	meeting := &calendar.MeetingInfo{Reminders: []time.Duration{30 * time.Minute, 10 * time.Minute, 0}}
+	noReminders := &calendar.MeetingInfo{}
+
+	tests := []struct {
+		name       string
+		thresholds Thresholds
+		meeting    *calendar.MeetingInfo
+		want       []time.Duration
+	}{
+		{"fixed only", Thresholds{Fixed: []time.Duration{5 * time.Minute}}, meeting, []time.Duration{5 * time.Minute}},
+		{"reminders only", Thresholds{Reminders: true}, meeting, []time.Duration{30 * time.Minute, 10 * time.Minute, 0}},
+		{"merged", Thresholds{Fixed: []time.Duration{10 * time.Minute, time.Minute}, Reminders: true}, meeting, []time.Duration{30 * time.Minute, 10 * time.Minute, time.Minute, 0}},
+		{"meeting without reminders", Thresholds{Reminders: true}, noReminders, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.thresholds.For(tt.meeting); !slices.Equal(got, tt.want) {
+				t.Errorf("For() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShouldNotifyReminders(t *testing.T) {
+	_ = Clear()
+	defer Clear()
+
+	now := time.Date(2026, 1, 9, 13, 45, 0, 0, time.UTC)
+	withReminder := &calendar.MeetingInfo{Summary: "Review", Start: now.Add(15 * time.Minute), End: now.Add(time.Hour), Reminders: []time.Duration{15 * time.Minute}}
+	without := &calendar.MeetingInfo{Summary: "Sync", Start: now.Add(15 * time.Minute), End: now.Add(time.Hour)}
+	thresholds := Thresholds{Fixed: []time.Duration{time.Minute}, Reminders: true}
+
+	alerts := ShouldNotify(calendar.GetMeetingStatus([]*calendar.MeetingInfo{withReminder, without}, now), thresholds, now)
+	if len(alerts) != 1 || alerts[0].Meeting != withReminder || alerts[0].Threshold != 15*time.Minute || alerts[0].Last {
+		t.Fatalf("expected only the 15m reminder as a heads-up, got %+v", alerts)
+	}
+}
+
+func TestShouldNotifyReminderAtStart(t *testing.T) {
+	_ = Clear()
+	defer Clear()
+
+	start := time.Date(2026, 1, 9, 14, 0, 0, 0, time.UTC)
+	meeting := &calendar.MeetingInfo{Summary: "Review", Start: start, End: start.Add(time.Hour), Reminders: []time.Duration{0}}
+	other := &calendar.MeetingInfo{Summary: "Sync", Start: start, End: start.Add(time.Hour)}
+	events := []*calendar.MeetingInfo{meeting, other}
+	thresholds := Thresholds{Reminders: true}
+
+	tests := []struct {
+		name string
+		at   time.Duration
+		want bool
+	}{
+		{"before the start", -time.Minute, false},
+		{"at the start", 0, true},
+		{"shortly after the start", time.Minute, true},
+		{"too late", StartGrace, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := start.Add(tt.at)
+			alerts := ShouldNotify(calendar.GetMeetingStatus(events, now), thresholds, now)
+			if !tt.want {
+				if len(alerts) != 0 {
+					t.Fatalf("expected no alerts, got %+v", alerts)
+				}
+				return
+			}
+			if len(alerts) != 1 || alerts[0].Meeting != meeting || alerts[0].Threshold != 0 || !alerts[0].Last {
+				t.Fatalf("expected the reminder at the start as the final alert, got %+v", alerts)
+			}
+		})
+	}
+}
+
 func TestEnsureDefaultIconCreatesFile(t *testing.T) {
 	p := filepath.Join(os.TempDir(), "next-meeting-icon.png")
 	_ = os.Remove(p)
@@ -79,3 +353,111 @@ func TestEnsureDefaultIconCreatesFile(t *testing.T) {
 
 	_ = os.Remove(icon)
 }
+
+func TestMarkNotifiedEncrypted(t *testing.T) {
+	keyring.MockInit()
+	_ = Clear()
+	defer Clear()
+	SetEncryption(true)
+	defer SetEncryption(false)
+
+	m := &calendar.MeetingInfo{
+		Summary: "Confidential Customer Call",
+		Start:   time.Now().Add(1 * time.Hour),
+		End:     time.Now().Add(2 * time.Hour),
+	}
+
+	if err := MarkNotified(m, time.Minute); err != nil {
+		t.Fatalf("MarkNotified failed: %v", err)
+	}
+
+	if !HasBeenNotified(m, time.Minute) {
+		t.Fatalf("expected notified after MarkNotified")
+	}
+
+	data, err := os.ReadFile(getNotifyFilePath(m, time.Minute))
+	if err != nil {
+		t.Fatalf("expected notify file to exist: %v", err)
+	}
+	if strings.Contains(string(data), "Confidential") {
+		t.Fatalf("expected notify marker to be encrypted, got %q", data)
+	}
+}
+
+func TestShouldNotifyConflicts(t *testing.T) {
+	_ = Clear()
+	defer Clear()
+
+	morning := time.Date(2026, 1, 9, 8, 0, 0, 0, time.UTC)
+	events := []*calendar.MeetingInfo{
+		{Summary: "Standup", Start: morning.Add(time.Hour), End: morning.Add(90 * time.Minute)},
+		{Summary: "Interview", Start: morning.Add(time.Hour), End: morning.Add(2 * time.Hour)},
+		{Summary: "Lunch", Start: morning.Add(4 * time.Hour), End: morning.Add(5 * time.Hour)},
+	}
+
+	conflicts := ShouldNotifyConflicts(events, morning)
+	if len(conflicts) != 1 {
+		t.Fatalf("expected 1 conflict, got %d", len(conflicts))
+	}
+
+	if err := MarkConflictsNotified(morning); err != nil {
+		t.Fatalf("MarkConflictsNotified failed: %v", err)
+	}
+	if conflicts := ShouldNotifyConflicts(events, morning.Add(30*time.Minute)); conflicts != nil {
+		t.Fatalf("expected no conflicts after marking the day notified, got %d", len(conflicts))
+	}
+
+	// The next day is announced separately
+	nextDay := make([]*calendar.MeetingInfo, len(events))
+	for i, event := range events {
+		nextDay[i] = &calendar.MeetingInfo{Summary: event.Summary, Start: event.Start.AddDate(0, 0, 1), End: event.End.AddDate(0, 0, 1)}
+	}
+	if conflicts := ShouldNotifyConflicts(nextDay, morning.AddDate(0, 0, 1)); len(conflicts) != 1 {
+		t.Fatalf("expected 1 conflict the next day, got %d", len(conflicts))
+	}
+}
+
+func TestShouldNotifyConflictsIgnoresPast(t *testing.T) {
+	_ = Clear()
+	defer Clear()
+
+	now := time.Date(2026, 1, 9, 12, 0, 0, 0, time.UTC)
+	events := []*calendar.MeetingInfo{
+		{Summary: "A", Start: now.Add(-2 * time.Hour), End: now.Add(-time.Hour)},
+		{Summary: "B", Start: now.Add(-90 * time.Minute), End: now.Add(-30 * time.Minute)},
+	}
+
+	if conflicts := ShouldNotifyConflicts(events, now); conflicts != nil {
+		t.Fatalf("expected past conflicts to be ignored, got %d", len(conflicts))
+	}
+}
+
+func TestShouldNotifyChain(t *testing.T) {
+	_ = Clear()
+	defer Clear()
+
+	now := time.Date(2026, 1, 9, 12, 50, 0, 0, time.UTC)
+	chain := &calendar.Chain{
+		Interval: calendar.Interval{Start: now.Add(10 * time.Minute), End: now.Add(190 * time.Minute)},
+	}
+
+	if ShouldNotifyChain(nil, 15*time.Minute, now) {
+		t.Fatalf("expected no notification without a chain")
+	}
+	if ShouldNotifyChain(chain, 5*time.Minute, now) {
+		t.Fatalf("expected no notification before the threshold")
+	}
+	if !ShouldNotifyChain(chain, 15*time.Minute, now) {
+		t.Fatalf("expected notification within the threshold")
+	}
+	if ShouldNotifyChain(chain, 15*time.Minute, now.Add(20*time.Minute)) {
+		t.Fatalf("expected no notification once the chain started")
+	}
+
+	if err := MarkChainNotified(chain); err != nil {
+		t.Fatalf("MarkChainNotified failed: %v", err)
+	}
+	if ShouldNotifyChain(chain, 15*time.Minute, now) {
+		t.Fatalf("expected no notification after marking the chain notified")
+	}
+}
@@ -0,0 +1,218 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"next-meeting/calendar"
+)
+
+// AgendaEntry is one meeting of a day's agenda
+type AgendaEntry struct {
+	Meeting   *calendar.MeetingInfo
+	Current   bool
+	Conflicts []*calendar.MeetingInfo // Other meetings overlapping this one
+	GapBefore time.Duration           // Free time since the previous meetings ended
+}
+
+// NewAgenda lists events by start time, marking the current meeting,
+// conflicts and the gaps between meetings
+func NewAgenda(events []*calendar.MeetingInfo, now time.Time) []*AgendaEntry {
+	sorted := make([]*calendar.MeetingInfo, len(events))
+	copy(sorted, events)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Start.Before(sorted[j].Start)
+	})
+
+	status := calendar.GetMeetingStatus(sorted, now)
+	conflicts := make(map[*calendar.MeetingInfo][]*calendar.MeetingInfo)
+	for _, c := range calendar.ConflictPairs(sorted) {
+		conflicts[c.A] = append(conflicts[c.A], c.B)
+		conflicts[c.B] = append(conflicts[c.B], c.A)
+	}
+
+	var entries []*AgendaEntry
+	var busyUntil time.Time
+	for i, meeting := range sorted {
+		entry := &AgendaEntry{
+			Meeting:   meeting,
+			Current:   meeting == status.CurrentMeeting,
+			Conflicts: conflicts[meeting],
+		}
+		if i > 0 && meeting.Start.After(busyUntil) {
+			entry.GapBefore = meeting.Start.Sub(busyUntil)
+		}
+		if meeting.End.After(busyUntil) {
+			busyUntil = meeting.End
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// agendaTitle is the heading shown above an agenda
+func agendaTitle(day time.Time) string {
+	return "📅 " + day.Format("Monday, 2 January 2006")
+}
+
+// agendaMarker marks the current meeting and meetings with conflicts
+func agendaMarker(entry *AgendaEntry) string {
+	marker := ""
+	if entry.Current {
+		marker += "▶"
+	}
+	if len(entry.Conflicts) > 0 {
+		marker += "⚠"
+	}
+	return marker
+}
+
+// agendaNotes describes the conflicts of an entry
+func agendaNotes(entry *AgendaEntry) string {
+	if len(entry.Conflicts) == 0 {
+		return ""
+	}
+	var names []string
+	for _, other := range entry.Conflicts {
+		names = append(names, other.Summary)
+	}
+	return "overlaps " + strings.Join(names, ", ")
+}
+
+// agendaWarnings describes each double-booking of the day
+func agendaWarnings(entries []*AgendaEntry) []string {
+	meetings := make([]*calendar.MeetingInfo, 0, len(entries))
+	for _, entry := range entries {
+		meetings = append(meetings, entry.Meeting)
+	}
+
+	var warnings []string
+	for _, conflict := range calendar.ConflictPairs(meetings) {
+		overlap := conflict.Overlap()
+		warnings = append(warnings, fmt.Sprintf("⚠ Double-booked %s–%s: %s and %s",
+			overlap.Start.Format("15:04"), overlap.End.Format("15:04"), conflict.A.Summary, conflict.B.Summary))
+	}
+	return warnings
+}
+
+// agendaColumns returns the table cells describing a meeting
+func agendaColumns(entry *AgendaEntry) []string {
+	m := entry.Meeting
+	return []string{
+		agendaMarker(entry),
+		m.Start.Format("15:04") + "–" + m.End.Format("15:04"),
+		calendar.FormatDuration(m.End.Sub(m.Start)),
+		m.Summary,
+		m.SelfResponseStatus,
+		strconv.Itoa(m.Attendees),
+		m.Location,
+		m.Conference,
+		agendaNotes(entry),
+	}
+}
+
+var agendaHeader = []string{"", "TIME", "DURATION", "TITLE", "RESPONSE", "ATTENDEES", "LOCATION", "CONFERENCE", "NOTES"}
+
+// AgendaTable renders an agenda as an aligned plain text table
+func AgendaTable(day time.Time, entries []*AgendaEntry) string {
+	var sb strings.Builder
+	sb.WriteString(agendaTitle(day) + "\n\n")
+	if len(entries) == 0 {
+		sb.WriteString("📭 No meetings\n")
+		return sb.String()
+	}
+
+	var table strings.Builder
+	w := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(agendaHeader, "\t"))
+	for _, entry := range entries {
+		if entry.GapBefore > 0 {
+			fmt.Fprintf(w, "\t\t%s\t· free\t\t\t\t\t\n", calendar.FormatDuration(entry.GapBefore))
+		}
+		fmt.Fprintln(w, strings.Join(agendaColumns(entry), "\t"))
+	}
+	_ = w.Flush()
+
+	// Empty trailing cells leave padding behind
+	for _, line := range strings.Split(strings.TrimSuffix(table.String(), "\n"), "\n") {
+		sb.WriteString(strings.TrimRight(line, " ") + "\n")
+	}
+
+	if warnings := agendaWarnings(entries); len(warnings) > 0 {
+		sb.WriteString("\n" + strings.Join(warnings, "\n") + "\n")
+	}
+	return sb.String()
+}
+
+// AgendaMarkdown renders an agenda as a Markdown table
+func AgendaMarkdown(day time.Time, entries []*AgendaEntry) string {
+	var sb strings.Builder
+	sb.WriteString("## " + agendaTitle(day) + "\n\n")
+	if len(entries) == 0 {
+		sb.WriteString("📭 No meetings\n")
+		return sb.String()
+	}
+
+	header := []string{"", "Time", "Duration", "Title", "Response", "Attendees", "Location", "Conference", "Notes"}
+	sb.WriteString("| " + strings.Join(header, " | ") + " |\n")
+	sb.WriteString(strings.Repeat("|---", len(header)) + "|\n")
+	for _, entry := range entries {
+		if entry.GapBefore > 0 {
+			sb.WriteString(fmt.Sprintf("|  |  | %s | *free* |  |  |  |  |  |\n", calendar.FormatDuration(entry.GapBefore)))
+		}
+		cells := agendaColumns(entry)
+		for i, cell := range cells {
+			cells[i] = strings.ReplaceAll(cell, "|", `\|`)
+		}
+		sb.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+
+	if warnings := agendaWarnings(entries); len(warnings) > 0 {
+		sb.WriteString("\n")
+		for _, warning := range warnings {
+			sb.WriteString("> " + warning + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// AgendaMeeting is the JSON representation of an agenda entry
+type AgendaMeeting struct {
+	*Meeting
+	Current          bool     `json:"current"`
+	ConflictsWith    []string `json:"conflicts_with"`
+	GapBeforeSeconds int64    `json:"gap_before_seconds"`
+}
+
+// Agenda is the JSON document printed by "agenda --output json"
+type Agenda struct {
+	SchemaVersion int              `json:"schema_version"`
+	Date          string           `json:"date"`
+	Meetings      []*AgendaMeeting `json:"meetings"`
+}
+
+// NewAgendaJSON builds the JSON document for an agenda
+func NewAgendaJSON(day time.Time, entries []*AgendaEntry, now time.Time) *Agenda {
+	agenda := &Agenda{
+		SchemaVersion: SchemaVersion,
+		Date:          day.Format(time.DateOnly),
+		Meetings:      make([]*AgendaMeeting, 0, len(entries)),
+	}
+	for _, entry := range entries {
+		conflicts := make([]string, 0, len(entry.Conflicts))
+		for _, other := range entry.Conflicts {
+			conflicts = append(conflicts, other.Summary)
+		}
+		agenda.Meetings = append(agenda.Meetings, &AgendaMeeting{
+			Meeting:          NewMeeting(entry.Meeting, now),
+			Current:          entry.Current,
+			ConflictsWith:    conflicts,
+			GapBeforeSeconds: int64(entry.GapBefore.Seconds()),
+		})
+	}
+	return agenda
+}
@@ -0,0 +1,163 @@
+package output
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"next-meeting/calendar"
+)
+
+func agendaTestEvents(day time.Time) []*calendar.MeetingInfo {
+	at := func(hour, minute int) time.Time {
+		return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, day.Location())
+	}
+	return []*calendar.MeetingInfo{
+		{Summary: "Lunch", Start: at(12, 0), End: at(13, 0), SelfResponseStatus: "accepted"},
+		{Summary: "Standup", Start: at(9, 0), End: at(9, 15), SelfResponseStatus: "accepted", Attendees: 6, Conference: "Google Meet"},
+		{Summary: "Design Review", Start: at(10, 0), End: at(11, 0), SelfResponseStatus: "tentative", Attendees: 3, Location: "Room 1"},
+		{Summary: "Customer Call", Start: at(10, 30), End: at(11, 30), SelfResponseStatus: "accepted", Attendees: 2, Conference: "Zoom"},
+	}
+}
+
+func TestNewAgenda(t *testing.T) {
+	day := time.Date(2026, 1, 9, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2026, 1, 9, 10, 45, 0, 0, time.UTC)
+
+	entries := NewAgenda(agendaTestEvents(day), now)
+
+	wantOrder := []string{"Standup", "Design Review", "Customer Call", "Lunch"}
+	if len(entries) != len(wantOrder) {
+		t.Fatalf("expected %d entries, got %d", len(wantOrder), len(entries))
+	}
+	for i, entry := range entries {
+		if entry.Meeting.Summary != wantOrder[i] {
+			t.Errorf("entries[%d] = %q, want %q", i, entry.Meeting.Summary, wantOrder[i])
+		}
+	}
+
+	tests := []struct {
+		summary       string
+		wantCurrent   bool
+		wantConflicts int
+		wantGap       time.Duration
+	}{
+		{"Standup", false, 0, 0},
+		{"Design Review", false, 1, 45 * time.Minute},
+		{"Customer Call", true, 1, 0},
+		{"Lunch", false, 0, 30 * time.Minute},
+	}
+	for i, tt := range tests {
+		entry := entries[i]
+		if entry.Current != tt.wantCurrent {
+			t.Errorf("%s: Current = %v, want %v", tt.summary, entry.Current, tt.wantCurrent)
+		}
+		if len(entry.Conflicts) != tt.wantConflicts {
+			t.Errorf("%s: %d conflicts, want %d", tt.summary, len(entry.Conflicts), tt.wantConflicts)
+		}
+		if entry.GapBefore != tt.wantGap {
+			t.Errorf("%s: GapBefore = %v, want %v", tt.summary, entry.GapBefore, tt.wantGap)
+		}
+	}
+}
+
+func TestAgendaTable(t *testing.T) {
+	day := time.Date(2026, 1, 9, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2026, 1, 9, 10, 45, 0, 0, time.UTC)
+
+	got := AgendaTable(day, NewAgenda(agendaTestEvents(day), now))
+	want := `📅 Friday, 9 January 2026
+
+    TIME         DURATION  TITLE          RESPONSE   ATTENDEES  LOCATION  CONFERENCE   NOTES
+    09:00–09:15  15m       Standup        accepted   6                    Google Meet
+                 45m       · free
+⚠   10:00–11:00  1h0m      Design Review  tentative  3          Room 1                 overlaps Customer Call
+▶⚠  10:30–11:30  1h0m      Customer Call  accepted   2                    Zoom         overlaps Design Review
+                 30m       · free
+    12:00–13:00  1h0m      Lunch          accepted   0
+
+⚠ Double-booked 10:30–11:00: Design Review and Customer Call
+`
+	if got != want {
+		t.Errorf("AgendaTable() =\n%s\nwant\n%s", got, want)
+	}
+
+	empty := AgendaTable(day, nil)
+	if !strings.Contains(empty, "📭 No meetings") {
+		t.Errorf("expected empty agenda to say there are no meetings, got %q", empty)
+	}
+}
+
+func TestAgendaMarkdown(t *testing.T) {
+	day := time.Date(2026, 1, 9, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2026, 1, 9, 8, 0, 0, 0, time.UTC)
+	events := []*calendar.MeetingInfo{
+		{Summary: "A | B", Start: day.Add(9 * time.Hour), End: day.Add(10 * time.Hour), SelfResponseStatus: "accepted"},
+		{Summary: "C", Start: day.Add(11 * time.Hour), End: day.Add(12 * time.Hour), SelfResponseStatus: "accepted"},
+	}
+
+	got := AgendaMarkdown(day, NewAgenda(events, now))
+	want := `## 📅 Friday, 9 January 2026
+
+|  | Time | Duration | Title | Response | Attendees | Location | Conference | Notes |
+|---|---|---|---|---|---|---|---|---|
+|  | 09:00–10:00 | 1h0m | A \| B | accepted | 0 |  |  |  |
+|  |  | 1h0m | *free* |  |  |  |  |  |
+|  | 11:00–12:00 | 1h0m | C | accepted | 0 |  |  |  |
+`
+	if got != want {
+		t.Errorf("AgendaMarkdown() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestAgendaMarkdownConflicts(t *testing.T) {
+	day := time.Date(2026, 1, 9, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2026, 1, 9, 8, 0, 0, 0, time.UTC)
+
+	got := AgendaMarkdown(day, NewAgenda(agendaTestEvents(day), now))
+	want := "\n> ⚠ Double-booked 10:30–11:00: Design Review and Customer Call\n"
+	if !strings.HasSuffix(got, want) {
+		t.Errorf("expected AgendaMarkdown() to end with %q, got\n%s", want, got)
+	}
+}
+
+func TestNewAgendaJSON(t *testing.T) {
+	day := time.Date(2026, 1, 9, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2026, 1, 9, 10, 45, 0, 0, time.UTC)
+
+	agenda := NewAgendaJSON(day, NewAgenda(agendaTestEvents(day), now), now)
+	data, err := json.Marshal(agenda)
+	if err != nil {
+		t.Fatalf("failed to marshal agenda: %v", err)
+	}
+
+	var decoded struct {
+		SchemaVersion int    `json:"schema_version"`
+		Date          string `json:"date"`
+		Meetings      []struct {
+			Summary          string   `json:"summary"`
+			Current          bool     `json:"current"`
+			ConflictsWith    []string `json:"conflicts_with"`
+			GapBeforeSeconds int64    `json:"gap_before_seconds"`
+			Conference       string   `json:"conference"`
+		} `json:"meetings"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("failed to decode agenda: %v", err)
+	}
+
+	if decoded.SchemaVersion != SchemaVersion || decoded.Date != "2026-01-09" || len(decoded.Meetings) != 4 {
+		t.Fatalf("unexpected agenda: %s", data)
+	}
+	call := decoded.Meetings[2]
+	if call.Summary != "Customer Call" || !call.Current || call.Conference != "Zoom" {
+		t.Errorf("unexpected meeting: %+v", call)
+	}
+	if len(call.ConflictsWith) != 1 || call.ConflictsWith[0] != "Design Review" {
+		t.Errorf("expected conflict with Design Review, got %v", call.ConflictsWith)
+	}
+	if decoded.Meetings[1].GapBeforeSeconds != 45*60 {
+		t.Errorf("expected 45m gap before Design Review, got %ds", decoded.Meetings[1].GapBeforeSeconds)
+	}
+}
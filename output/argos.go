@@ -0,0 +1,63 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+
+	"next-meeting/calendar"
+)
+
+// Argos renders a multi-line menu for Argos, xbar and compatible hosts: the
+// status line, then one entry per remaining meeting today linking to its
+// conference, then entries to refresh and log in. command is how to invoke
+// this binary from the menu.
+func Argos(line string, status *calendar.MeetingStatus, command string) string {
+	var sb strings.Builder
+	sb.WriteString(argosText(line) + "\n")
+	sb.WriteString("---\n")
+
+	for _, meeting := range status.Ongoing() {
+		sb.WriteString(argosMeeting("▶", meeting))
+	}
+	for _, meeting := range status.Upcoming {
+		sb.WriteString(argosMeeting(" ", meeting))
+	}
+	if status.CurrentMeeting == nil && len(status.Upcoming) == 0 {
+		sb.WriteString("No more meetings today\n")
+	}
+
+	sb.WriteString(argosActions(command))
+	return sb.String()
+}
+
+// ArgosState renders the menu for a state without meeting information, such
+// as being offline or logged out
+func ArgosState(text, command string) string {
+	return argosText(text) + "\n" + argosActions(command)
+}
+
+func argosMeeting(marker string, meeting *calendar.MeetingInfo) string {
+	item := fmt.Sprintf("%s %s–%s  %s", marker, meeting.Start.Format("15:04"), meeting.End.Format("15:04"), argosText(meeting.Summary))
+	if meeting.HangoutLink != "" {
+		item += " | href=" + meeting.HangoutLink
+	}
+	return item + "\n"
+}
+
+func argosActions(command string) string {
+	return "---\n" +
+		fmt.Sprintf("Refresh | bash=%s terminal=false refresh=true\n", argosBash(command, "--refresh")) +
+		fmt.Sprintf("Login | bash=%s terminal=true refresh=true\n", argosBash(command, "--login"))
+}
+
+// argosBash quotes a bash= parameter: the host unquotes the value once and
+// runs the result through a shell, so the command is quoted for both
+func argosBash(command, flag string) string {
+	return ShellQuote(ShellQuote(command) + " " + flag)
+}
+
+// argosText keeps text on a single line and stops it from being read as item parameters
+func argosText(text string) string {
+	text = strings.ReplaceAll(text, "\n", " ")
+	return strings.ReplaceAll(text, "|", "∣")
+}
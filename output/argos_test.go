@@ -0,0 +1,86 @@
+package output
+
+import (
+	"testing"
+	"time"
+
+	"next-meeting/calendar"
+)
+
+func TestArgos(t *testing.T) {
+	fixedNow := time.Date(2026, 1, 9, 14, 30, 0, 0, time.UTC)
+	events := []*calendar.MeetingInfo{
+		{Summary: "Done", Start: fixedNow.Add(-2 * time.Hour), End: fixedNow.Add(-time.Hour)},
+		{Summary: "Weekly Sync", Start: fixedNow.Add(-30 * time.Minute), End: fixedNow.Add(30 * time.Minute), HangoutLink: "https://meet.google.com/abc"},
+		{Summary: "Lunch | Team", Start: fixedNow.Add(time.Hour), End: fixedNow.Add(2 * time.Hour)},
+	}
+	status := calendar.GetMeetingStatus(events, fixedNow)
+
+	got := Argos("🔴 Weekly Sync (30m left)", status, "/usr/bin/next-meeting")
+	want := "🔴 Weekly Sync (30m left)\n" +
+		"---\n" +
+		"▶ 14:00–15:00  Weekly Sync | href=https://meet.google.com/abc\n" +
+		"  15:30–16:30  Lunch ∣ Team\n" +
+		"---\n" +
+		"Refresh | bash='/usr/bin/next-meeting --refresh' terminal=false refresh=true\n" +
+		"Login | bash='/usr/bin/next-meeting --login' terminal=true refresh=true\n"
+	if got != want {
+		t.Errorf("Argos() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestArgosParallel(t *testing.T) {
+	fixedNow := time.Date(2026, 1, 9, 14, 30, 0, 0, time.UTC)
+	events := []*calendar.MeetingInfo{
+		{Summary: "Workshop", Start: fixedNow.Add(-90 * time.Minute), End: fixedNow.Add(90 * time.Minute)},
+		{Summary: "Weekly Sync", Start: fixedNow.Add(-30 * time.Minute), End: fixedNow.Add(30 * time.Minute)},
+	}
+	status := calendar.GetMeetingStatus(events, fixedNow)
+
+	got := Argos("🔴 Weekly Sync (30m left) +1 parallel", status, "next-meeting")
+	want := "🔴 Weekly Sync (30m left) +1 parallel\n" +
+		"---\n" +
+		"▶ 14:00–15:00  Weekly Sync\n" +
+		"▶ 13:00–16:00  Workshop\n" +
+		"---\n" +
+		"Refresh | bash='next-meeting --refresh' terminal=false refresh=true\n" +
+		"Login | bash='next-meeting --login' terminal=true refresh=true\n"
+	if got != want {
+		t.Errorf("Argos() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestArgosEmpty(t *testing.T) {
+	got := Argos("📭 No meetings", &calendar.MeetingStatus{}, "next-meeting")
+	want := "📭 No meetings\n" +
+		"---\n" +
+		"No more meetings today\n" +
+		"---\n" +
+		"Refresh | bash='next-meeting --refresh' terminal=false refresh=true\n" +
+		"Login | bash='next-meeting --login' terminal=true refresh=true\n"
+	if got != want {
+		t.Errorf("Argos() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestArgosState(t *testing.T) {
+	got := ArgosState("🔒 Not logged in", "next-meeting")
+	want := "🔒 Not logged in\n" +
+		"---\n" +
+		"Refresh | bash='next-meeting --refresh' terminal=false refresh=true\n" +
+		"Login | bash='next-meeting --login' terminal=true refresh=true\n"
+	if got != want {
+		t.Errorf("ArgosState() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestArgosQuotesCommand(t *testing.T) {
+	got := ArgosState("🔒 Not logged in", "/home/me/it's mine/next-meeting")
+	want := "🔒 Not logged in\n" +
+		"---\n" +
+		`Refresh | bash=''\''/home/me/it'\''\'\'''\''s mine/next-meeting'\'' --refresh' terminal=false refresh=true` + "\n" +
+		`Login | bash=''\''/home/me/it'\''\'\'''\''s mine/next-meeting'\'' --login' terminal=true refresh=true` + "\n"
+	if got != want {
+		t.Errorf("ArgosState() =\n%s\nwant\n%s", got, want)
+	}
+}
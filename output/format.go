@@ -0,0 +1,207 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"next-meeting/calendar"
+)
+
+// Separator is placed between the segments of the default status line
+const Separator = " │ "
+
+// partMark stands in for Separator in the template text while rendering, so
+// separators written by the template can be told apart from ones inside
+// meeting titles
+const partMark = "\x1f"
+
+const (
+	currentSegment = `🔴 {{.Current.Summary}} {{if lt (minutes .CurrentLeft) 1}}finishing now{{else}}({{duration .CurrentLeft}} left){{end}}{{if .Parallel}} +{{.Parallel}} parallel{{end}}`
+	nextSegment    = `🕐 {{.Next.Summary}} {{if lt (minutes .NextIn) 1}}starting now{{else}}in {{duration .NextIn}}{{end}}`
+
+	backToBackSegment = `{{if .BackToBack}}` + Separator + `⚠ {{duration .BackToBack}} back-to-back{{end}}`
+
+	// DefaultInMeetingTemplate is used while a meeting is in progress
+	DefaultInMeetingTemplate = currentSegment + `{{if .Next}}` + Separator + nextSegment + `{{end}}` + backToBackSegment
+	// DefaultUpcomingTemplate is used when there is no current meeting but one is coming up
+	DefaultUpcomingTemplate = nextSegment + backToBackSegment
+	// DefaultEmptyTemplate is used when there are no more meetings
+	DefaultEmptyTemplate = `📭 No meetings`
+
+	// FreeSegment shows the free block before the next meeting
+	FreeSegment = `{{if .FreeFor}}` + Separator + `🟢 free for {{duration .FreeFor}}{{end}}`
+	// FreeSlotSegment shows the next long enough free slot when booked solid
+	FreeSlotSegment = `{{with .FreeSlot}}` + Separator + `🟢 free at {{formatTime "15:04" .Start}} for {{duration .Duration}}{{end}}`
+)
+
+// Options tune what NewData calculates
+type Options struct {
+	FreeSlot time.Duration // Find the next free slot of at least this length when the free block is shorter
+
+	BackToBackLimit time.Duration // Warn about chains of meetings longer than this; zero disables the warning
+	MinBreak        time.Duration // Gaps shorter than this don't break a chain of meetings
+}
+
+// Data is what templates have access to
+type Data struct {
+	Now         time.Time
+	Current     *calendar.MeetingInfo
+	Next        *calendar.MeetingInfo
+	CurrentLeft time.Duration // Time until the current meeting ends
+	NextIn      time.Duration // Time until the next meeting starts
+	CurrentLink string        // Conference link of the current meeting
+	NextLink    string        // Conference link of the next meeting
+	Remaining   int           // Meetings today that have not ended yet, including the current one
+	Total       int           // All meetings today
+	Parallel    int           // Other meetings overlapping the current one
+	FreeFor     time.Duration // Free time until the next meeting, zero while busy or with no more meetings
+	FreeSlot    *calendar.Interval
+	Chain       *calendar.Chain // Back-to-back meetings longer than the limit, in progress or starting next
+	BackToBack  time.Duration   // Length of Chain
+}
+
+// NewData builds template data from the events and the status calculated from them
+func NewData(events []*calendar.MeetingInfo, status *calendar.MeetingStatus, now time.Time, opts Options) *Data {
+	data := &Data{
+		Now:      now,
+		Current:  status.CurrentMeeting,
+		Next:     status.NextMeeting,
+		Total:    len(events),
+		Parallel: len(status.Parallel),
+	}
+
+	if data.Current != nil {
+		data.CurrentLeft = data.Current.End.Sub(now)
+		data.CurrentLink = data.Current.HangoutLink
+	}
+	if data.Next != nil {
+		data.NextIn = data.Next.Start.Sub(now)
+		data.NextLink = data.Next.HangoutLink
+	}
+
+	for _, event := range events {
+		if now.Before(event.End) {
+			data.Remaining++
+		}
+	}
+
+	data.FreeFor, _ = calendar.FreeFor(events, now)
+	if opts.FreeSlot > 0 && data.FreeFor < opts.FreeSlot && data.Remaining > 0 {
+		year, month, day := now.Date()
+		midnight := time.Date(year, month, day+1, 0, 0, 0, 0, now.Location())
+		if slot, ok := calendar.NextFreeSlot(events, now, opts.FreeSlot, midnight); ok {
+			data.FreeSlot = &slot
+		}
+	}
+
+	if opts.BackToBackLimit > 0 {
+		chains := calendar.BackToBackChains(events, opts.MinBreak)
+		if chain := calendar.LongChainAt(chains, opts.BackToBackLimit, now, data.Next); chain != nil {
+			data.Chain = chain
+			data.BackToBack = chain.Duration()
+		}
+	}
+
+	return data
+}
+
+// Templates holds the template source for each state
+type Templates struct {
+	InMeeting string
+	Upcoming  string
+	Empty     string
+}
+
+// DefaultTemplates returns the templates that produce the classic status line
+func DefaultTemplates() Templates {
+	return Templates{
+		InMeeting: DefaultInMeetingTemplate,
+		Upcoming:  DefaultUpcomingTemplate,
+		Empty:     DefaultEmptyTemplate,
+	}
+}
+
+// Funcs are the helper functions available to templates
+var Funcs = template.FuncMap{
+	"truncate": truncate,
+	"duration": calendar.FormatDuration,
+	"minutes":  func(d time.Duration) int { return int(d.Minutes()) },
+	"upper":    strings.ToUpper,
+	"lower":    strings.ToLower,
+	"formatTime": func(layout string, t time.Time) string {
+		return t.Format(layout)
+	},
+}
+
+// truncate shortens s to at most n characters, adding an ellipsis when cut
+func truncate(n int, s string) string {
+	runes := []rune(s)
+	if n <= 0 || len(runes) <= n {
+		return s
+	}
+	if n == 1 {
+		return "…"
+	}
+	return string(runes[:n-1]) + "…"
+}
+
+// Formatter renders the status line using a template per state
+type Formatter struct {
+	inMeeting *template.Template
+	upcoming  *template.Template
+	empty     *template.Template
+}
+
+// NewFormatter parses the given templates
+func NewFormatter(t Templates) (*Formatter, error) {
+	var f Formatter
+	var err error
+
+	if f.inMeeting, err = parse("in-meeting", t.InMeeting); err != nil {
+		return nil, err
+	}
+	if f.upcoming, err = parse("upcoming", t.Upcoming); err != nil {
+		return nil, err
+	}
+	if f.empty, err = parse("empty", t.Empty); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+func parse(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(Funcs).Parse(strings.ReplaceAll(text, Separator, partMark))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s template: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+// Format renders the template matching the state described by data
+func (f *Formatter) Format(data *Data) (string, error) {
+	parts, err := f.Parts(data)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(parts, Separator), nil
+}
+
+// Parts renders the template matching the state described by data and splits
+// it at the separators in the template, leaving separators in data intact
+func (f *Formatter) Parts(data *Data) ([]string, error) {
+	tmpl := f.empty
+	switch {
+	case data.Current != nil:
+		tmpl = f.inMeeting
+	case data.Next != nil:
+		tmpl = f.upcoming
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return nil, fmt.Errorf("failed to render %s template: %w", tmpl.Name(), err)
+	}
+	return strings.Split(sb.String(), partMark), nil
+}
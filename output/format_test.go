@@ -0,0 +1,417 @@
+package output
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"next-meeting/calendar"
+)
+
+func TestDefaultTemplates(t *testing.T) {
+	fixedNow := time.Date(2026, 1, 9, 14, 30, 0, 0, time.UTC)
+
+	makeMeeting := func(summary string, startOffset, endOffset time.Duration) *calendar.MeetingInfo {
+		return &calendar.MeetingInfo{
+			Summary: summary,
+			Start:   fixedNow.Add(startOffset),
+			End:     fixedNow.Add(endOffset),
+		}
+	}
+
+	tests := []struct {
+		name   string
+		events []*calendar.MeetingInfo
+		want   string
+	}{
+		{
+			name: "no meetings",
+			want: "📭 No meetings",
+		},
+		{
+			name: "only past meetings",
+			events: []*calendar.MeetingInfo{
+				makeMeeting("Past", -2*time.Hour, -1*time.Hour),
+			},
+			want: "📭 No meetings",
+		},
+		{
+			name: "current meeting",
+			events: []*calendar.MeetingInfo{
+				makeMeeting("Weekly Sync", -25*time.Minute, 5*time.Minute),
+			},
+			want: "🔴 Weekly Sync (5m left)",
+		},
+		{
+			name: "current meeting finishing",
+			events: []*calendar.MeetingInfo{
+				makeMeeting("Weekly Sync", -30*time.Minute, 30*time.Second),
+			},
+			want: "🔴 Weekly Sync finishing now",
+		},
+		{
+			name: "upcoming meeting",
+			events: []*calendar.MeetingInfo{
+				makeMeeting("Team Standup", 10*time.Minute, 25*time.Minute),
+			},
+			want: "🕐 Team Standup in 10m",
+		},
+		{
+			name: "upcoming meeting starting",
+			events: []*calendar.MeetingInfo{
+				makeMeeting("Team Standup", 30*time.Second, 15*time.Minute),
+			},
+			want: "🕐 Team Standup starting now",
+		},
+		{
+			name: "current and next meeting",
+			events: []*calendar.MeetingInfo{
+				makeMeeting("Weekly Sync", -25*time.Minute, 5*time.Minute),
+				makeMeeting("Lunch", 65*time.Minute, 125*time.Minute),
+			},
+			want: "🔴 Weekly Sync (5m left) │ 🕐 Lunch in 1h5m",
+		},
+		{
+			name: "parallel meetings",
+			events: []*calendar.MeetingInfo{
+				makeMeeting("All Hands", -time.Hour, time.Hour),
+				makeMeeting("Weekly Sync", -25*time.Minute, 5*time.Minute),
+			},
+			want: "🔴 Weekly Sync (5m left) +1 parallel",
+		},
+		{
+			name: "meeting starting during the current one",
+			events: []*calendar.MeetingInfo{
+				makeMeeting("Weekly Sync", -25*time.Minute, 35*time.Minute),
+				makeMeeting("Interview", 5*time.Minute, 65*time.Minute),
+			},
+			want: "🔴 Weekly Sync (35m left) +1 parallel │ 🕐 Interview in 5m",
+		},
+	}
+
+	f, err := NewFormatter(DefaultTemplates())
+	if err != nil {
+		t.Fatalf("NewFormatter failed: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := calendar.GetMeetingStatus(tt.events, fixedNow)
+			got, err := f.Format(NewData(tt.events, status, fixedNow, Options{}))
+			if err != nil {
+				t.Fatalf("Format failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Format() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCustomTemplates(t *testing.T) {
+	fixedNow := time.Date(2026, 1, 9, 14, 30, 0, 0, time.UTC)
+	events := []*calendar.MeetingInfo{
+		{Summary: "Done", Start: fixedNow.Add(-2 * time.Hour), End: fixedNow.Add(-time.Hour)},
+		{Summary: "Quarterly Business Review", Start: fixedNow.Add(-10 * time.Minute), End: fixedNow.Add(50 * time.Minute), HangoutLink: "https://meet.google.com/abc"},
+		{Summary: "Lunch", Start: fixedNow.Add(time.Hour), End: fixedNow.Add(2 * time.Hour)},
+	}
+	status := calendar.GetMeetingStatus(events, fixedNow)
+	data := NewData(events, status, fixedNow, Options{})
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{
+			name:     "truncate and upper",
+			template: `{{upper (truncate 10 .Current.Summary)}}`,
+			want:     "QUARTERLY…",
+		},
+		{
+			name:     "duration and time formatting",
+			template: `{{duration .CurrentLeft}} until {{formatTime "15:04" .Current.End}}`,
+			want:     "50m until 15:20",
+		},
+		{
+			name:     "links and counts",
+			template: `{{.CurrentLink}} {{.Remaining}}/{{.Total}}`,
+			want:     "https://meet.google.com/abc 2/3",
+		},
+		{
+			name:     "next meeting",
+			template: `{{with .Next}}{{lower .Summary}}{{end}} in {{minutes .NextIn}}`,
+			want:     "lunch in 60",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewFormatter(Templates{InMeeting: tt.template, Upcoming: tt.template, Empty: tt.template})
+			if err != nil {
+				t.Fatalf("NewFormatter failed: %v", err)
+			}
+			got, err := f.Format(data)
+			if err != nil {
+				t.Fatalf("Format failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Format() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFreeSegments(t *testing.T) {
+	fixedNow := time.Date(2026, 1, 9, 14, 30, 0, 0, time.UTC)
+
+	makeMeeting := func(summary string, startOffset, endOffset time.Duration) *calendar.MeetingInfo {
+		return &calendar.MeetingInfo{
+			Summary: summary,
+			Start:   fixedNow.Add(startOffset),
+			End:     fixedNow.Add(endOffset),
+		}
+	}
+
+	tests := []struct {
+		name     string
+		events   []*calendar.MeetingInfo
+		freeSlot time.Duration
+		want     string
+	}{
+		{
+			name: "free before next meeting",
+			events: []*calendar.MeetingInfo{
+				makeMeeting("Team Standup", 45*time.Minute, time.Hour),
+			},
+			want: "🕐 Team Standup in 45m │ 🟢 free for 45m",
+		},
+		{
+			name: "in meeting without free slot option",
+			events: []*calendar.MeetingInfo{
+				makeMeeting("Weekly Sync", -25*time.Minute, 5*time.Minute),
+			},
+			want: "🔴 Weekly Sync (5m left)",
+		},
+		{
+			name: "in meeting shows next free slot",
+			events: []*calendar.MeetingInfo{
+				makeMeeting("Weekly Sync", -25*time.Minute, 5*time.Minute),
+				makeMeeting("Review", 10*time.Minute, 90*time.Minute),
+			},
+			freeSlot: 30 * time.Minute,
+			want:     "🔴 Weekly Sync (5m left) │ 🕐 Review in 10m │ 🟢 free at 16:00 for 8h0m",
+		},
+		{
+			name: "short free block shows next free slot",
+			events: []*calendar.MeetingInfo{
+				makeMeeting("Review", 10*time.Minute, 90*time.Minute),
+				makeMeeting("Planning", 2*time.Hour, 9*time.Hour),
+			},
+			freeSlot: 30 * time.Minute,
+			want:     "🕐 Review in 10m │ 🟢 free for 10m │ 🟢 free at 16:00 for 30m",
+		},
+		{
+			name: "long free block hides free slot",
+			events: []*calendar.MeetingInfo{
+				makeMeeting("Review", time.Hour, 90*time.Minute),
+			},
+			freeSlot: 30 * time.Minute,
+			want:     "🕐 Review in 1h0m │ 🟢 free for 1h0m",
+		},
+	}
+
+	f, err := NewFormatter(Templates{
+		InMeeting: DefaultInMeetingTemplate + FreeSlotSegment,
+		Upcoming:  DefaultUpcomingTemplate + FreeSegment + FreeSlotSegment,
+		Empty:     DefaultEmptyTemplate,
+	})
+	if err != nil {
+		t.Fatalf("NewFormatter failed: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := calendar.GetMeetingStatus(tt.events, fixedNow)
+			got, err := f.Format(NewData(tt.events, status, fixedNow, Options{FreeSlot: tt.freeSlot}))
+			if err != nil {
+				t.Fatalf("Format failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Format() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackToBackSegment(t *testing.T) {
+	fixedNow := time.Date(2026, 1, 9, 12, 30, 0, 0, time.UTC)
+
+	makeMeeting := func(summary string, startOffset, endOffset time.Duration) *calendar.MeetingInfo {
+		return &calendar.MeetingInfo{
+			Summary: summary,
+			Start:   fixedNow.Add(startOffset),
+			End:     fixedNow.Add(endOffset),
+		}
+	}
+	events := []*calendar.MeetingInfo{
+		makeMeeting("Planning", 30*time.Minute, 90*time.Minute),
+		makeMeeting("Review", 95*time.Minute, 150*time.Minute),
+		makeMeeting("Retro", 150*time.Minute, 210*time.Minute),
+	}
+
+	tests := []struct {
+		name  string
+		now   time.Duration
+		limit time.Duration
+		want  string
+	}{
+		{
+			name: "warning disabled",
+			want: "🕐 Planning in 30m",
+		},
+		{
+			name:  "chain starting next",
+			limit: 2 * time.Hour,
+			want:  "🕐 Planning in 30m │ ⚠ 3h0m back-to-back",
+		},
+		{
+			name:  "inside the chain",
+			now:   time.Hour,
+			limit: 2 * time.Hour,
+			want:  "🔴 Planning (30m left) │ 🕐 Review in 35m │ ⚠ 3h0m back-to-back",
+		},
+		{
+			name:  "chain within the limit",
+			limit: 3 * time.Hour,
+			want:  "🕐 Planning in 30m",
+		},
+	}
+
+	f, err := NewFormatter(DefaultTemplates())
+	if err != nil {
+		t.Fatalf("NewFormatter failed: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := fixedNow.Add(tt.now)
+			status := calendar.GetMeetingStatus(events, now)
+			opts := Options{BackToBackLimit: tt.limit, MinBreak: 10 * time.Minute}
+			got, err := f.Format(NewData(events, status, now, opts))
+			if err != nil {
+				t.Fatalf("Format failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Format() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPerStateTemplates(t *testing.T) {
+	fixedNow := time.Date(2026, 1, 9, 14, 30, 0, 0, time.UTC)
+	f, err := NewFormatter(Templates{InMeeting: "busy", Upcoming: "soon", Empty: "free"})
+	if err != nil {
+		t.Fatalf("NewFormatter failed: %v", err)
+	}
+
+	current := &calendar.MeetingInfo{Summary: "Now", Start: fixedNow.Add(-time.Minute), End: fixedNow.Add(time.Minute)}
+	next := &calendar.MeetingInfo{Summary: "Later", Start: fixedNow.Add(time.Hour), End: fixedNow.Add(2 * time.Hour)}
+
+	tests := []struct {
+		name   string
+		status *calendar.MeetingStatus
+		want   string
+	}{
+		{"in meeting", &calendar.MeetingStatus{CurrentMeeting: current, NextMeeting: next}, "busy"},
+		{"upcoming", &calendar.MeetingStatus{NextMeeting: next}, "soon"},
+		{"empty", &calendar.MeetingStatus{}, "free"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := f.Format(NewData(nil, tt.status, fixedNow, Options{}))
+			if err != nil {
+				t.Fatalf("Format failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Format() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewFormatterInvalidTemplate(t *testing.T) {
+	if _, err := NewFormatter(Templates{InMeeting: "{{.Current", Upcoming: "", Empty: ""}); err == nil {
+		t.Errorf("expected error for invalid template")
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		n    int
+		s    string
+		want string
+	}{
+		{5, "Hello", "Hello"},
+		{4, "Hello", "Hel…"},
+		{1, "Hello", "…"},
+		{0, "Hello", "Hello"},
+		{3, "Café au lait", "Ca…"},
+	}
+
+	for _, tt := range tests {
+		if got := truncate(tt.n, tt.s); got != tt.want {
+			t.Errorf("truncate(%d, %q) = %q, want %q", tt.n, tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestParts(t *testing.T) {
+	fixedNow := time.Date(2026, 1, 9, 14, 30, 0, 0, time.UTC)
+	events := []*calendar.MeetingInfo{
+		{Summary: "Design" + Separator + "Review", Start: fixedNow.Add(-10 * time.Minute), End: fixedNow.Add(20 * time.Minute)},
+		{Summary: "Lunch", Start: fixedNow.Add(time.Hour), End: fixedNow.Add(2 * time.Hour)},
+	}
+	status := calendar.GetMeetingStatus(events, fixedNow)
+	data := NewData(events, status, fixedNow, Options{})
+
+	tests := []struct {
+		name     string
+		template string
+		want     []string
+	}{
+		{
+			name:     "default",
+			template: DefaultInMeetingTemplate,
+			want:     []string{"🔴 Design" + Separator + "Review (20m left)", "🕐 Lunch in 1h0m"},
+		},
+		{
+			name:     "custom",
+			template: `{{.Current.Summary}}` + Separator + `{{.Remaining}} left`,
+			want:     []string{"Design" + Separator + "Review", "2 left"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewFormatter(Templates{InMeeting: tt.template})
+			if err != nil {
+				t.Fatalf("NewFormatter failed: %v", err)
+			}
+			got, err := f.Parts(data)
+			if err != nil {
+				t.Fatalf("Parts failed: %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Parts() = %q, want %q", got, tt.want)
+			}
+			line, _ := f.Format(data)
+			if want := strings.Join(tt.want, Separator); line != want {
+				t.Errorf("Format() = %q, want %q", line, want)
+			}
+		})
+	}
+}
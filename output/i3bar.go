@@ -0,0 +1,66 @@
+package output
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"next-meeting/calendar"
+)
+
+// I3barBlockName identifies our block in the i3bar protocol
+const I3barBlockName = "next-meeting"
+
+// Mouse buttons reported in i3bar click events
+const (
+	I3barButtonLeft  = 1
+	I3barButtonRight = 3
+)
+
+// I3barHeader is the first message of the i3bar protocol
+type I3barHeader struct {
+	Version     int  `json:"version"`
+	ClickEvents bool `json:"click_events"`
+}
+
+// I3barBlock is a single block of an i3bar status line
+type I3barBlock struct {
+	Name     string `json:"name"`
+	FullText string `json:"full_text"`
+	Urgent   bool   `json:"urgent"`
+}
+
+// I3barClick is a click event sent by i3bar on stdin
+type I3barClick struct {
+	Name   string `json:"name"`
+	Button int    `json:"button"`
+}
+
+// NewI3barBlock builds the block for a meeting status. The block is urgent
+// while a meeting is about to start.
+func NewI3barBlock(text string, status *calendar.MeetingStatus, now time.Time, soon time.Duration) *I3barBlock {
+	return &I3barBlock{
+		Name:     I3barBlockName,
+		FullText: text,
+		Urgent:   State(status, now, soon) == StateSoon,
+	}
+}
+
+// ParseI3barClick parses one line of the click event stream, which is an
+// infinite JSON array with one event per line. Returns nil for lines that
+// don't hold an event, such as the opening bracket.
+func ParseI3barClick(line string) *I3barClick {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "[")
+	line = strings.TrimPrefix(line, ",")
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return nil
+	}
+
+	var click I3barClick
+	if err := json.Unmarshal([]byte(line), &click); err != nil {
+		return nil
+	}
+	return &click
+}
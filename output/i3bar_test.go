@@ -0,0 +1,55 @@
+package output
+
+import (
+	"testing"
+	"time"
+
+	"next-meeting/calendar"
+)
+
+func TestParseI3barClick(t *testing.T) {
+	tests := []struct {
+		name       string
+		line       string
+		wantNil    bool
+		wantButton int
+	}{
+		{name: "opening bracket", line: "[", wantNil: true},
+		{name: "empty line", line: "", wantNil: true},
+		{name: "first event", line: `{"name":"next-meeting","button":1,"x":10}`, wantButton: 1},
+		{name: "event with leading comma", line: `,{"name":"next-meeting","button":3}`, wantButton: 3},
+		{name: "event on opening line", line: `[{"name":"next-meeting","button":1}`, wantButton: 1},
+		{name: "garbage", line: "not json", wantNil: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			click := ParseI3barClick(tt.line)
+			if tt.wantNil {
+				if click != nil {
+					t.Errorf("expected nil, got %+v", click)
+				}
+				return
+			}
+			if click == nil {
+				t.Fatalf("expected click event")
+			}
+			if click.Button != tt.wantButton || click.Name != I3barBlockName {
+				t.Errorf("unexpected click event %+v", click)
+			}
+		})
+	}
+}
+
+func TestNewI3barBlockUrgent(t *testing.T) {
+	fixedNow := time.Date(2026, 1, 9, 14, 30, 0, 0, time.UTC)
+	soon := &calendar.MeetingStatus{NextMeeting: &calendar.MeetingInfo{Start: fixedNow.Add(2 * time.Minute)}}
+	later := &calendar.MeetingStatus{NextMeeting: &calendar.MeetingInfo{Start: fixedNow.Add(time.Hour)}}
+
+	if !NewI3barBlock("x", soon, fixedNow, 5*time.Minute).Urgent {
+		t.Errorf("expected block to be urgent when a meeting is about to start")
+	}
+	if NewI3barBlock("x", later, fixedNow, 5*time.Minute).Urgent {
+		t.Errorf("expected block not to be urgent when the next meeting is far away")
+	}
+}
@@ -0,0 +1,112 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"next-meeting/calendar"
+)
+
+// SchemaVersion is increased whenever the JSON output changes incompatibly
+const SchemaVersion = 1
+
+// Error codes reported in JSON output
+const (
+	ErrorNotLoggedIn = "not_logged_in"
+	ErrorOffline     = "offline"
+	ErrorGeneric     = "error"
+)
+
+// Meeting is the JSON representation of a calendar.MeetingInfo
+type Meeting struct {
+	ID                string    `json:"id"`
+	Summary           string    `json:"summary"`
+	Start             time.Time `json:"start"`
+	End               time.Time `json:"end"`
+	AllDay            bool      `json:"all_day"`
+	Location          string    `json:"location"`
+	Link              string    `json:"link"`
+	Attendees         int       `json:"attendees"`
+	ResponseStatus    string    `json:"response_status"`
+	Conference        string    `json:"conference"`
+	SecondsUntilStart int64     `json:"seconds_until_start"` // Negative once the meeting has started
+	SecondsUntilEnd   int64     `json:"seconds_until_end"`
+}
+
+// Error describes why no status could be produced
+type Error struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Status is the JSON document printed by --output json
+type Status struct {
+	SchemaVersion   int        `json:"schema_version"`
+	LoggedIn        bool       `json:"logged_in"`
+	Offline         bool       `json:"offline"`
+	CacheAgeSeconds int64      `json:"cache_age_seconds"` // 0 when events were just fetched
+	Current         *Meeting   `json:"current"`
+	Next            *Meeting   `json:"next"`
+	Upcoming        []*Meeting `json:"upcoming"`
+	Parallel        []*Meeting `json:"parallel"` // Other meetings overlapping the current one
+	Error           *Error     `json:"error,omitempty"`
+}
+
+// NewMeeting converts a meeting to its JSON representation at the given time
+func NewMeeting(meeting *calendar.MeetingInfo, now time.Time) *Meeting {
+	if meeting == nil {
+		return nil
+	}
+	return &Meeting{
+		ID:                meeting.ID,
+		Summary:           meeting.Summary,
+		Start:             meeting.Start,
+		End:               meeting.End,
+		AllDay:            meeting.IsAllDay,
+		Location:          meeting.Location,
+		Link:              meeting.HangoutLink,
+		Attendees:         meeting.Attendees,
+		ResponseStatus:    meeting.SelfResponseStatus,
+		Conference:        meeting.Conference,
+		SecondsUntilStart: int64(meeting.Start.Sub(now).Seconds()),
+		SecondsUntilEnd:   int64(meeting.End.Sub(now).Seconds()),
+	}
+}
+
+// NewStatus builds the JSON document for a meeting status
+func NewStatus(status *calendar.MeetingStatus, now time.Time, cacheAge time.Duration) *Status {
+	s := &Status{
+		SchemaVersion:   SchemaVersion,
+		LoggedIn:        true,
+		CacheAgeSeconds: int64(cacheAge.Seconds()),
+		Current:         NewMeeting(status.CurrentMeeting, now),
+		Next:            NewMeeting(status.NextMeeting, now),
+		Upcoming:        make([]*Meeting, 0, len(status.Upcoming)),
+		Parallel:        make([]*Meeting, 0, len(status.Parallel)),
+	}
+	for _, meeting := range status.Upcoming {
+		s.Upcoming = append(s.Upcoming, NewMeeting(meeting, now))
+	}
+	for _, meeting := range status.Parallel {
+		s.Parallel = append(s.Parallel, NewMeeting(meeting, now))
+	}
+	return s
+}
+
+// NewErrorStatus builds the JSON document reported when no status is available
+func NewErrorStatus(code, message string) *Status {
+	return &Status{
+		SchemaVersion: SchemaVersion,
+		LoggedIn:      code != ErrorNotLoggedIn,
+		Offline:       code == ErrorOffline,
+		Upcoming:      []*Meeting{},
+		Parallel:      []*Meeting{},
+		Error:         &Error{Code: code, Message: message},
+	}
+}
+
+// WriteJSON writes v as a single line of JSON
+func WriteJSON(w io.Writer, v any) error {
+	return json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,107 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"next-meeting/calendar"
+)
+
+func TestNewStatus(t *testing.T) {
+	fixedNow := time.Date(2026, 1, 9, 14, 30, 0, 0, time.UTC)
+	events := []*calendar.MeetingInfo{
+		{
+			Summary:            "Weekly Sync",
+			Start:              fixedNow.Add(-25 * time.Minute),
+			End:                fixedNow.Add(5 * time.Minute),
+			HangoutLink:        "https://meet.google.com/abc",
+			Attendees:          4,
+			SelfResponseStatus: "accepted",
+		},
+		{Summary: "Lunch", Start: fixedNow.Add(time.Hour), End: fixedNow.Add(2 * time.Hour)},
+		{Summary: "Review", Start: fixedNow.Add(3 * time.Hour), End: fixedNow.Add(4 * time.Hour)},
+	}
+	status := calendar.GetMeetingStatus(events, fixedNow)
+
+	s := NewStatus(status, fixedNow, 2*time.Minute)
+
+	if s.SchemaVersion != SchemaVersion {
+		t.Errorf("expected schema version %d, got %d", SchemaVersion, s.SchemaVersion)
+	}
+	if !s.LoggedIn || s.Offline || s.Error != nil {
+		t.Errorf("expected logged in, online status without error, got %+v", s)
+	}
+	if s.CacheAgeSeconds != 120 {
+		t.Errorf("expected cache age 120s, got %d", s.CacheAgeSeconds)
+	}
+	if s.Current == nil || s.Current.Summary != "Weekly Sync" {
+		t.Fatalf("expected current meeting Weekly Sync, got %+v", s.Current)
+	}
+	if s.Current.SecondsUntilStart != -25*60 || s.Current.SecondsUntilEnd != 5*60 {
+		t.Errorf("unexpected current meeting timing: %+v", s.Current)
+	}
+	if s.Current.Link != "https://meet.google.com/abc" || s.Current.ResponseStatus != "accepted" || s.Current.Attendees != 4 {
+		t.Errorf("unexpected current meeting details: %+v", s.Current)
+	}
+	if s.Next == nil || s.Next.Summary != "Lunch" || s.Next.SecondsUntilStart != 3600 {
+		t.Errorf("expected next meeting Lunch in 3600s, got %+v", s.Next)
+	}
+	if len(s.Upcoming) != 2 || s.Upcoming[1].Summary != "Review" {
+		t.Errorf("expected 2 upcoming meetings, got %+v", s.Upcoming)
+	}
+	if len(s.Parallel) != 0 {
+		t.Errorf("expected no parallel meetings, got %+v", s.Parallel)
+	}
+}
+
+func TestNewStatusEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, NewStatus(&calendar.MeetingStatus{}, time.Now(), 0)); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+
+	var decoded map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+
+	if decoded["current"] != nil || decoded["next"] != nil {
+		t.Errorf("expected null current and next, got %v", decoded)
+	}
+	if upcoming, ok := decoded["upcoming"].([]any); !ok || len(upcoming) != 0 {
+		t.Errorf("expected empty upcoming array, got %v", decoded["upcoming"])
+	}
+	if _, ok := decoded["error"]; ok {
+		t.Errorf("expected no error field, got %v", decoded["error"])
+	}
+}
+
+func TestNewErrorStatus(t *testing.T) {
+	tests := []struct {
+		code         string
+		wantLoggedIn bool
+		wantOffline  bool
+	}{
+		{ErrorNotLoggedIn, false, false},
+		{ErrorOffline, true, true},
+		{ErrorGeneric, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			s := NewErrorStatus(tt.code, "message")
+			if s.LoggedIn != tt.wantLoggedIn || s.Offline != tt.wantOffline {
+				t.Errorf("NewErrorStatus(%q) = logged_in %v offline %v, want %v %v",
+					tt.code, s.LoggedIn, s.Offline, tt.wantLoggedIn, tt.wantOffline)
+			}
+			if s.Error == nil || s.Error.Code != tt.code {
+				t.Errorf("expected error code %q, got %+v", tt.code, s.Error)
+			}
+			if s.Upcoming == nil || s.Parallel == nil {
+				t.Errorf("expected empty upcoming and parallel lists, got %v and %v", s.Upcoming, s.Parallel)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package output
+
+import (
+	"strings"
+	"time"
+
+	"next-meeting/calendar"
+)
+
+// Segment is one part of the status line together with the state that decides its color
+type Segment struct {
+	Text  string
+	State string
+}
+
+// Segments assigns states to the parts of a status line, as returned by
+// Formatter.Parts. While in a meeting the first segment is the current meeting
+// and the rest are colored by how soon the next meeting starts; otherwise
+// every segment uses the overall state.
+func Segments(parts []string, status *calendar.MeetingStatus, now time.Time, soon time.Duration) []Segment {
+	state := State(status, now, soon)
+	nextState := State(&calendar.MeetingStatus{NextMeeting: status.NextMeeting}, now, soon)
+
+	var segments []Segment
+	for i, text := range parts {
+		segmentState := state
+		if state == StateInMeeting && i > 0 {
+			segmentState = nextState
+		}
+		segments = append(segments, Segment{Text: text, State: segmentState})
+	}
+	return segments
+}
+
+// Polybar wraps each segment in polybar color markup and makes the whole line
+// run joinCommand on left click
+func Polybar(segments []Segment, colors map[string]string, joinCommand string) string {
+	var parts []string
+	for _, segment := range segments {
+		text := strings.ReplaceAll(segment.Text, "%", "%%")
+		if color := colors[segment.State]; color != "" {
+			text = "%{F" + color + "}" + text + "%{F-}"
+		}
+		parts = append(parts, text)
+	}
+
+	line := strings.Join(parts, Separator)
+	if joinCommand == "" {
+		return line
+	}
+	action := strings.ReplaceAll(joinCommand, ":", `\:`)
+	return "%{A1:" + action + ":}" + line + "%{A}"
+}
+
+// TmuxJoinRange names the status line range tmux reports when the meeting is clicked
+const TmuxJoinRange = "join"
+
+// Tmux wraps each segment in tmux style markup and marks the whole line as a
+// user range named TmuxJoinRange so a mouse binding can run "join"
+func Tmux(segments []Segment, colors map[string]string) string {
+	var parts []string
+	for _, segment := range segments {
+		text := strings.ReplaceAll(segment.Text, "#", "##")
+		if color := colors[segment.State]; color != "" {
+			text = "#[fg=" + color + "]" + text + "#[default]"
+		}
+		parts = append(parts, text)
+	}
+	return "#[range=user|" + TmuxJoinRange + "]" + strings.Join(parts, Separator) + "#[norange]"
+}
+
+// ShellQuote quotes s as a single sh word if it contains anything but
+// characters that are safe unquoted
+func ShellQuote(s string) string {
+	safe := func(r rune) bool {
+		return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("/._-+:@%=,", r)
+	}
+	if s != "" && !strings.ContainsFunc(s, func(r rune) bool { return !safe(r) }) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
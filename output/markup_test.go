@@ -0,0 +1,114 @@
+package output
+
+import (
+	"testing"
+	"time"
+
+	"next-meeting/calendar"
+)
+
+var testColors = map[string]string{
+	StateInMeeting: "#ff0000",
+	StateSoon:      "#ffaa00",
+	StateFree:      "#00ff00",
+}
+
+func TestSegments(t *testing.T) {
+	fixedNow := time.Date(2026, 1, 9, 14, 30, 0, 0, time.UTC)
+	current := &calendar.MeetingInfo{Summary: "Sync", Start: fixedNow.Add(-time.Minute), End: fixedNow.Add(time.Minute)}
+	soon := &calendar.MeetingInfo{Summary: "Soon", Start: fixedNow.Add(5 * time.Minute), End: fixedNow.Add(time.Hour)}
+	later := &calendar.MeetingInfo{Summary: "Later", Start: fixedNow.Add(time.Hour), End: fixedNow.Add(2 * time.Hour)}
+
+	tests := []struct {
+		name   string
+		parts  []string
+		status *calendar.MeetingStatus
+		want   []Segment
+	}{
+		{
+			name:   "empty",
+			parts:  []string{"📭 No meetings"},
+			status: &calendar.MeetingStatus{},
+			want:   []Segment{{"📭 No meetings", StateFree}},
+		},
+		{
+			name:   "current and next soon",
+			parts:  []string{"a", "b"},
+			status: &calendar.MeetingStatus{CurrentMeeting: current, NextMeeting: soon},
+			want:   []Segment{{"a", StateInMeeting}, {"b", StateSoon}},
+		},
+		{
+			name:   "current and next later",
+			parts:  []string{"a", "b"},
+			status: &calendar.MeetingStatus{CurrentMeeting: current, NextMeeting: later},
+			want:   []Segment{{"a", StateInMeeting}, {"b", StateFree}},
+		},
+		{
+			name:   "only next soon",
+			parts:  []string{"b"},
+			status: &calendar.MeetingStatus{NextMeeting: soon},
+			want:   []Segment{{"b", StateSoon}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Segments(tt.parts, tt.status, fixedNow, 10*time.Minute)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Segments() returned %d segments, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Segments()[%d] = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestPolybar(t *testing.T) {
+	segments := []Segment{{"🔴 Sync (5m left)", StateInMeeting}, {"🕐 100% review in 1h", StateFree}}
+
+	got := Polybar(segments, testColors, "/usr/bin/next-meeting join")
+	want := "%{A1:/usr/bin/next-meeting join:}%{F#ff0000}🔴 Sync (5m left)%{F-}" + Separator +
+		"%{F#00ff00}🕐 100%% review in 1h%{F-}%{A}"
+	if got != want {
+		t.Errorf("Polybar() = %q, want %q", got, want)
+	}
+
+	t.Run("colons in command are escaped", func(t *testing.T) {
+		got := Polybar([]Segment{{"x", "unknown"}}, testColors, `C:\next-meeting join`)
+		want := `%{A1:C\:\next-meeting join:}x%{A}`
+		if got != want {
+			t.Errorf("Polybar() = %q, want %q", got, want)
+		}
+	})
+}
+
+func TestTmux(t *testing.T) {
+	segments := []Segment{{"🔴 #1 Sync", StateInMeeting}, {"🕐 Lunch", StateSoon}}
+
+	got := Tmux(segments, testColors)
+	want := "#[range=user|join]#[fg=#ff0000]🔴 ##1 Sync#[default]" + Separator +
+		"#[fg=#ffaa00]🕐 Lunch#[default]#[norange]"
+	if got != want {
+		t.Errorf("Tmux() = %q, want %q", got, want)
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"/usr/bin/next-meeting", "/usr/bin/next-meeting"},
+		{"/home/me/My Apps/next-meeting", "'/home/me/My Apps/next-meeting'"},
+		{"/opt/it's/next-meeting", `'/opt/it'\''s/next-meeting'`},
+		{"", "''"},
+	}
+	for _, tt := range tests {
+		if got := ShellQuote(tt.in); got != tt.want {
+			t.Errorf("ShellQuote(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
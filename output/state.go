@@ -0,0 +1,73 @@
+package output
+
+import (
+	"time"
+
+	"next-meeting/calendar"
+)
+
+// States describe the situation shown by status bars, e.g. as CSS classes or colors
+const (
+	StateInMeeting = "in-meeting"
+	StateSoon      = "soon"
+	StateFree      = "free"
+	StateOffline   = "offline"
+	StateLoggedOut = "logged-out"
+	StateError     = "error"
+)
+
+// State returns the state for a meeting status. A meeting starting within
+// soon of now makes the state StateSoon.
+func State(status *calendar.MeetingStatus, now time.Time, soon time.Duration) string {
+	if status.CurrentMeeting != nil {
+		return StateInMeeting
+	}
+	if status.NextMeeting != nil && status.NextMeeting.Start.Sub(now) <= soon {
+		return StateSoon
+	}
+	return StateFree
+}
+
+// ChangeKey returns a key that changes whenever the state, current meeting or
+// next meeting changes, but not merely because time passes
+func ChangeKey(status *calendar.MeetingStatus, now time.Time, soon time.Duration) string {
+	return State(status, now, soon) + "|" + meetingKey(status.CurrentMeeting) + "|" + meetingKey(status.NextMeeting)
+}
+
+func meetingKey(meeting *calendar.MeetingInfo) string {
+	if meeting == nil {
+		return ""
+	}
+	return meeting.Summary + "@" + meeting.Start.Format(time.RFC3339) + "-" + meeting.End.Format(time.RFC3339)
+}
+
+// Progress returns how far through the current meeting we are, from 0 to 100
+func Progress(status *calendar.MeetingStatus, now time.Time) int {
+	meeting := status.CurrentMeeting
+	if meeting == nil {
+		return 0
+	}
+
+	total := meeting.End.Sub(meeting.Start)
+	if total <= 0 {
+		return 100
+	}
+	return int(100 * now.Sub(meeting.Start) / total)
+}
+
+// AgendaLines describes the meetings that have not ended yet, one per line.
+// Meetings in progress are marked with "▶".
+func AgendaLines(status *calendar.MeetingStatus) []string {
+	var lines []string
+	for _, meeting := range status.Ongoing() {
+		lines = append(lines, agendaLine("▶", meeting))
+	}
+	for _, meeting := range status.Upcoming {
+		lines = append(lines, agendaLine(" ", meeting))
+	}
+	return lines
+}
+
+func agendaLine(marker string, meeting *calendar.MeetingInfo) string {
+	return marker + " " + meeting.Start.Format("15:04") + "–" + meeting.End.Format("15:04") + "  " + meeting.Summary
+}
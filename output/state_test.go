@@ -0,0 +1,66 @@
+package output
+
+import (
+	"testing"
+	"time"
+
+	"next-meeting/calendar"
+)
+
+func TestChangeKey(t *testing.T) {
+	fixedNow := time.Date(2026, 1, 9, 14, 30, 0, 0, time.UTC)
+	soon := 10 * time.Minute
+	events := []*calendar.MeetingInfo{
+		{Summary: "Standup", Start: fixedNow.Add(20 * time.Minute), End: fixedNow.Add(35 * time.Minute)},
+		{Summary: "Review", Start: fixedNow.Add(time.Hour), End: fixedNow.Add(2 * time.Hour)},
+	}
+	key := func(offset time.Duration) string {
+		now := fixedNow.Add(offset)
+		return ChangeKey(calendar.GetMeetingStatus(events, now), now, soon)
+	}
+
+	tests := []struct {
+		name    string
+		a, b    time.Duration
+		changed bool
+	}{
+		{"time passes while free", 0, 5 * time.Minute, false},
+		{"meeting becomes soon", 5 * time.Minute, 15 * time.Minute, true},
+		{"meeting starts", 15 * time.Minute, 25 * time.Minute, true},
+		{"time passes in a meeting", 25 * time.Minute, 30 * time.Minute, false},
+		{"meeting ends", 30 * time.Minute, 40 * time.Minute, true},
+		{"last meeting ends", 90 * time.Minute, 3 * time.Hour, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if changed := key(tt.a) != key(tt.b); changed != tt.changed {
+				t.Errorf("key changed = %v, want %v (%q → %q)", changed, tt.changed, key(tt.a), key(tt.b))
+			}
+		})
+	}
+}
+
+func TestAgendaLines(t *testing.T) {
+	fixedNow := time.Date(2026, 1, 9, 14, 30, 0, 0, time.UTC)
+	events := []*calendar.MeetingInfo{
+		{Summary: "Workshop", Start: fixedNow.Add(-90 * time.Minute), End: fixedNow.Add(90 * time.Minute)},
+		{Summary: "Weekly Sync", Start: fixedNow.Add(-30 * time.Minute), End: fixedNow.Add(30 * time.Minute)},
+		{Summary: "Review", Start: fixedNow.Add(15 * time.Minute), End: fixedNow.Add(45 * time.Minute)},
+	}
+
+	got := AgendaLines(calendar.GetMeetingStatus(events, fixedNow))
+	want := []string{
+		"▶ 14:00–15:00  Weekly Sync",
+		"▶ 13:00–16:00  Workshop",
+		"  14:45–15:15  Review",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("AgendaLines() = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("line %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package output
+
+import (
+	"strings"
+	"time"
+
+	"next-meeting/calendar"
+)
+
+// Waybar is the JSON object read by Waybar custom modules with "return-type": "json"
+type Waybar struct {
+	Text       string `json:"text"`
+	Tooltip    string `json:"tooltip"`
+	Class      string `json:"class"`
+	Alt        string `json:"alt"`
+	Percentage int    `json:"percentage"`
+}
+
+// NewWaybar builds the Waybar module output. The tooltip lists the rest of
+// today's agenda and the percentage is the progress through the current meeting.
+func NewWaybar(text string, status *calendar.MeetingStatus, now time.Time, soon time.Duration) *Waybar {
+	state := State(status, now, soon)
+	tooltip := strings.Join(AgendaLines(status), "\n")
+	if tooltip == "" {
+		tooltip = "No more meetings today"
+	}
+
+	return &Waybar{
+		Text:       escapePango(text),
+		Tooltip:    escapePango(tooltip),
+		Class:      state,
+		Alt:        state,
+		Percentage: Progress(status, now),
+	}
+}
+
+// NewWaybarState builds the Waybar module output for a state without meeting
+// information, such as StateOffline or StateLoggedOut
+func NewWaybarState(text, state string) *Waybar {
+	return &Waybar{
+		Text:    escapePango(text),
+		Tooltip: escapePango(text),
+		Class:   state,
+		Alt:     state,
+	}
+}
+
+// pangoEscaper escapes the characters Pango markup treats specially, as
+// g_markup_escape_text does
+var pangoEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "'", "&apos;", `"`, "&quot;")
+
+// escapePango escapes s for Waybar, which renders text and tooltips as Pango markup
+func escapePango(s string) string {
+	return pangoEscaper.Replace(s)
+}
@@ -0,0 +1,107 @@
+package output
+
+import (
+	"testing"
+	"time"
+
+	"next-meeting/calendar"
+)
+
+func TestState(t *testing.T) {
+	fixedNow := time.Date(2026, 1, 9, 14, 30, 0, 0, time.UTC)
+	current := &calendar.MeetingInfo{Summary: "Now", Start: fixedNow.Add(-time.Minute), End: fixedNow.Add(time.Minute)}
+	soon := &calendar.MeetingInfo{Summary: "Soon", Start: fixedNow.Add(5 * time.Minute), End: fixedNow.Add(time.Hour)}
+	later := &calendar.MeetingInfo{Summary: "Later", Start: fixedNow.Add(time.Hour), End: fixedNow.Add(2 * time.Hour)}
+
+	tests := []struct {
+		name   string
+		status *calendar.MeetingStatus
+		soon   time.Duration
+		want   string
+	}{
+		{"no meetings", &calendar.MeetingStatus{}, 10 * time.Minute, StateFree},
+		{"in meeting", &calendar.MeetingStatus{CurrentMeeting: current}, 10 * time.Minute, StateInMeeting},
+		{"in meeting with next soon", &calendar.MeetingStatus{CurrentMeeting: current, NextMeeting: soon}, 10 * time.Minute, StateInMeeting},
+		{"next within threshold", &calendar.MeetingStatus{NextMeeting: soon}, 10 * time.Minute, StateSoon},
+		{"next exactly at threshold", &calendar.MeetingStatus{NextMeeting: soon}, 5 * time.Minute, StateSoon},
+		{"next beyond threshold", &calendar.MeetingStatus{NextMeeting: later}, 10 * time.Minute, StateFree},
+		{"larger threshold", &calendar.MeetingStatus{NextMeeting: later}, 2 * time.Hour, StateSoon},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := State(tt.status, fixedNow, tt.soon); got != tt.want {
+				t.Errorf("State() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProgress(t *testing.T) {
+	fixedNow := time.Date(2026, 1, 9, 14, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		meeting *calendar.MeetingInfo
+		want    int
+	}{
+		{"no meeting", nil, 0},
+		{"just started", &calendar.MeetingInfo{Start: fixedNow, End: fixedNow.Add(time.Hour)}, 0},
+		{"quarter through", &calendar.MeetingInfo{Start: fixedNow.Add(-15 * time.Minute), End: fixedNow.Add(45 * time.Minute)}, 25},
+		{"almost over", &calendar.MeetingInfo{Start: fixedNow.Add(-59 * time.Minute), End: fixedNow.Add(time.Minute)}, 98},
+		{"zero length", &calendar.MeetingInfo{Start: fixedNow, End: fixedNow}, 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := &calendar.MeetingStatus{CurrentMeeting: tt.meeting}
+			if got := Progress(status, fixedNow); got != tt.want {
+				t.Errorf("Progress() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewWaybar(t *testing.T) {
+	fixedNow := time.Date(2026, 1, 9, 14, 30, 0, 0, time.UTC)
+	events := []*calendar.MeetingInfo{
+		{Summary: "Done", Start: fixedNow.Add(-2 * time.Hour), End: fixedNow.Add(-time.Hour)},
+		{Summary: "Weekly Sync", Start: fixedNow.Add(-30 * time.Minute), End: fixedNow.Add(30 * time.Minute)},
+		{Summary: "Lunch", Start: fixedNow.Add(time.Hour), End: fixedNow.Add(2 * time.Hour)},
+	}
+	status := calendar.GetMeetingStatus(events, fixedNow)
+
+	w := NewWaybar("text", status, fixedNow, 10*time.Minute)
+
+	if w.Text != "text" {
+		t.Errorf("expected text to be passed through, got %q", w.Text)
+	}
+	if w.Class != StateInMeeting || w.Alt != StateInMeeting {
+		t.Errorf("expected class and alt %q, got %q and %q", StateInMeeting, w.Class, w.Alt)
+	}
+	if w.Percentage != 50 {
+		t.Errorf("expected 50%% progress, got %d", w.Percentage)
+	}
+	wantTooltip := "▶ 14:00–15:00  Weekly Sync\n  15:30–16:30  Lunch"
+	if w.Tooltip != wantTooltip {
+		t.Errorf("Tooltip = %q, want %q", w.Tooltip, wantTooltip)
+	}
+
+	escaped := NewWaybar("🔴 R&D <sync>", calendar.GetMeetingStatus([]*calendar.MeetingInfo{
+		{Summary: `R&D "sync"`, Start: fixedNow.Add(-30 * time.Minute), End: fixedNow.Add(30 * time.Minute)},
+	}, fixedNow), fixedNow, 10*time.Minute)
+	if escaped.Text != "🔴 R&amp;D &lt;sync&gt;" {
+		t.Errorf("expected escaped text, got %q", escaped.Text)
+	}
+	if want := "▶ 14:00–15:00  R&amp;D &quot;sync&quot;"; escaped.Tooltip != want {
+		t.Errorf("Tooltip = %q, want %q", escaped.Tooltip, want)
+	}
+	if state := NewWaybarState("R&D", StateOffline); state.Text != "R&amp;D" || state.Tooltip != "R&amp;D" {
+		t.Errorf("expected escaped state output, got %+v", state)
+	}
+
+	empty := NewWaybar("📭 No meetings", &calendar.MeetingStatus{}, fixedNow, 10*time.Minute)
+	if empty.Class != StateFree || empty.Tooltip != "No more meetings today" {
+		t.Errorf("unexpected empty output: %+v", empty)
+	}
+}
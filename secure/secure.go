@@ -0,0 +1,83 @@
+package secure
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sync"
+
+	"next-meeting/keyring"
+)
+
+// magic prefixes all sealed data so it can be told apart from plain JSON
+var magic = []byte("nm-sealed-v1:")
+
+var (
+	keyOnce sync.Once
+	key     []byte
+	keyErr  error
+)
+
+func getAEAD() (cipher.AEAD, error) {
+	keyOnce.Do(func() {
+		key, keyErr = keyring.LoadOrCreateDataKey()
+	})
+	if keyErr != nil {
+		return nil, fmt.Errorf("failed to load encryption key: %w", keyErr)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// IsSealed reports whether data was produced by Seal
+func IsSealed(data []byte) bool {
+	return bytes.HasPrefix(data, magic)
+}
+
+// Seal encrypts data with the key held in the system keyring
+func Seal(plaintext []byte) ([]byte, error) {
+	aead, err := getAEAD()
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	out := append([]byte{}, magic...)
+	out = append(out, nonce...)
+	return aead.Seal(out, nonce, plaintext, magic), nil
+}
+
+// Open decrypts data produced by Seal
+func Open(sealed []byte) ([]byte, error) {
+	if !IsSealed(sealed) {
+		return nil, errors.New("data is not encrypted")
+	}
+
+	aead, err := getAEAD()
+	if err != nil {
+		return nil, err
+	}
+
+	data := sealed[len(magic):]
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New("encrypted data is truncated")
+	}
+
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, magic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt data: %w", err)
+	}
+	return plaintext, nil
+}
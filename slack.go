@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"next-meeting/config"
+	"next-meeting/keyring"
+	"next-meeting/slack"
+	"next-meeting/transition"
+)
+
+// syncSlack sets or clears the Slack status for the given transitions. The
+// token is only read from the keyring when a meeting starts or ends.
+func syncSlack(ctx context.Context, cfg *config.Config, transitions []transition.Transition) error {
+	relevant := slices.ContainsFunc(transitions, func(t transition.Transition) bool {
+		return t.Kind == transition.MeetingStarted || t.Kind == transition.MeetingEnded || t.Kind == transition.DayFree
+	})
+	if !relevant {
+		return nil
+	}
+
+	sync, err := newSlackSync(cfg)
+	if err != nil {
+		return err
+	}
+	return sync.Apply(ctx, transitions)
+}
+
+// newSlackSync creates a Slack status sync using the token from the keyring
+func newSlackSync(cfg *config.Config) (*slack.Sync, error) {
+	token, err := keyring.LoadSlackToken()
+	if err != nil {
+		return nil, fmt.Errorf("no Slack token, run \"next-meeting slack login\": %w", err)
+	}
+	return slack.NewSync(slack.NewClient(cfg.SlackBaseURL, token), cfg.SlackStatusText, cfg.SlackStatusEmoji)
+}
+
+// runSlackCommand handles the "slack login", "slack logout" and "slack clear" subcommands
+func runSlackCommand(ctx context.Context, cfg *config.Config, args []string) {
+	if len(args) == 0 {
+		errorAndExit("%v\n", errors.New("usage: next-meeting slack login|logout|clear"))
+	}
+
+	switch args[0] {
+	case "login":
+		fmt.Fprint(os.Stderr, "Slack user token (xoxp-...): ")
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		token := strings.TrimSpace(line)
+		if token == "" {
+			if err == nil {
+				err = errors.New("empty token")
+			}
+			errorAndExit("Error reading Slack token: %v\n", err)
+		}
+		if err := keyring.SaveSlackToken(token); err != nil {
+			errorAndExit("Error saving Slack token: %v\n", err)
+		}
+		fmt.Println("✓ Slack token saved")
+	case "logout":
+		if err := keyring.DeleteSlackToken(); err != nil {
+			errorAndExit("Error clearing Slack token: %v\n", err)
+		}
+		fmt.Println("✓ Slack token cleared")
+	case "clear":
+		sync, err := newSlackSync(cfg)
+		if err != nil {
+			errorAndExit("Error: %v\n", err)
+		}
+		if err := sync.Clear(ctx); err != nil {
+			errorAndExit("Error: %v\n", err)
+		}
+		fmt.Println("✓ Slack status cleared")
+	default:
+		errorAndExit("%v\n", fmt.Errorf("unknown slack command %q", args[0]))
+	}
+}
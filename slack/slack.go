@@ -0,0 +1,157 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"next-meeting/calendar"
+	"next-meeting/output"
+	"next-meeting/transition"
+)
+
+const (
+	// DefaultBaseURL is the Slack Web API
+	DefaultBaseURL = "https://slack.com/api"
+	// DefaultStatusText is the status text template used unless configured otherwise
+	DefaultStatusText = "In a meeting"
+	// DefaultStatusEmoji is the status emoji used unless configured otherwise
+	DefaultStatusEmoji = ":spiral_calendar_pad:"
+
+	requestTimeout = 10 * time.Second
+)
+
+// Client talks to the Slack Web API
+type Client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// NewClient creates a client for the API at baseURL authenticating with token
+func NewClient(baseURL, token string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   token,
+		http:    &http.Client{Timeout: requestTimeout},
+	}
+}
+
+type profile struct {
+	StatusText       string `json:"status_text"`
+	StatusEmoji      string `json:"status_emoji"`
+	StatusExpiration int64  `json:"status_expiration"`
+}
+
+type apiResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+}
+
+// SetStatus sets the user's status until expiration. A zero expiration never expires.
+func (c *Client) SetStatus(ctx context.Context, text, emoji string, expiration time.Time) error {
+	p := profile{StatusText: text, StatusEmoji: emoji}
+	if !expiration.IsZero() {
+		p.StatusExpiration = expiration.Unix()
+	}
+	body, err := json.Marshal(map[string]profile{"profile": p})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/users.profile.set", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("setting Slack status: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("setting Slack status: %s", resp.Status)
+	}
+	var result apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("setting Slack status: invalid response: %w", err)
+	}
+	if !result.OK {
+		return fmt.Errorf("setting Slack status: %s", result.Error)
+	}
+	return nil
+}
+
+// ClearStatus removes the user's status
+func (c *Client) ClearStatus(ctx context.Context) error {
+	return c.SetStatus(ctx, "", "", time.Time{})
+}
+
+// Sync keeps the Slack status in line with meetings
+type Sync struct {
+	client *Client
+	text   *template.Template
+	emoji  string
+}
+
+// NewSync creates a sync that sets a status rendered from the text template
+// (with the meeting as data) and emoji while in a meeting
+func NewSync(client *Client, text, emoji string) (*Sync, error) {
+	if text == "" {
+		text = DefaultStatusText
+	}
+	if emoji == "" {
+		emoji = DefaultStatusEmoji
+	}
+	tmpl, err := template.New("slack").Funcs(output.Funcs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Slack status template: %w", err)
+	}
+	return &Sync{client: client, text: tmpl, emoji: emoji}, nil
+}
+
+// StatusText renders the status text for a meeting
+func (s *Sync) StatusText(meeting *calendar.MeetingInfo) (string, error) {
+	var sb strings.Builder
+	if err := s.text.Execute(&sb, meeting); err != nil {
+		return "", fmt.Errorf("failed to render Slack status: %w", err)
+	}
+	return sb.String(), nil
+}
+
+// Apply sets the status when a meeting starts, expiring when it ends, and
+// clears it when a meeting ends or the day is free. Only the last of these in
+// transitions matters, so a meeting ending into the next one just sets the
+// new status.
+func (s *Sync) Apply(ctx context.Context, transitions []transition.Transition) error {
+	for _, t := range slices.Backward(transitions) {
+		switch t.Kind {
+		case transition.MeetingStarted:
+			text, err := s.StatusText(t.Meeting)
+			if err != nil {
+				return err
+			}
+			return s.client.SetStatus(ctx, text, s.emoji, t.Meeting.End)
+		case transition.MeetingEnded, transition.DayFree:
+			return s.client.ClearStatus(ctx)
+		}
+	}
+	return nil
+}
+
+// Clear removes the status
+func (s *Sync) Clear(ctx context.Context) error {
+	return s.client.ClearStatus(ctx)
+}
@@ -0,0 +1,157 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"next-meeting/calendar"
+	"next-meeting/transition"
+)
+
+// fakeSlack records the profiles set through users.profile.set
+type fakeSlack struct {
+	profiles []profile
+	auth     []string
+	reply    string
+}
+
+func (f *fakeSlack) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/users.profile.set" || r.Method != http.MethodPost {
+		http.NotFound(w, r)
+		return
+	}
+	var body struct {
+		Profile profile `json:"profile"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	f.profiles = append(f.profiles, body.Profile)
+	f.auth = append(f.auth, r.Header.Get("Authorization"))
+	reply := f.reply
+	if reply == "" {
+		reply = `{"ok":true}`
+	}
+	_, _ = w.Write([]byte(reply))
+}
+
+func newFake(t *testing.T) (*fakeSlack, *Client) {
+	t.Helper()
+	fake := &fakeSlack{}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+	return fake, NewClient(srv.URL+"/", "xoxp-test")
+}
+
+func TestSetStatus(t *testing.T) {
+	fake, client := newFake(t)
+	end := time.Date(2026, 1, 9, 10, 0, 0, 0, time.UTC)
+
+	if err := client.SetStatus(context.Background(), "In Standup", ":calendar:", end); err != nil {
+		t.Fatalf("SetStatus failed: %v", err)
+	}
+	if err := client.ClearStatus(context.Background()); err != nil {
+		t.Fatalf("ClearStatus failed: %v", err)
+	}
+
+	want := []profile{
+		{StatusText: "In Standup", StatusEmoji: ":calendar:", StatusExpiration: end.Unix()},
+		{},
+	}
+	if len(fake.profiles) != len(want) {
+		t.Fatalf("expected %d requests, got %d", len(want), len(fake.profiles))
+	}
+	for i := range want {
+		if fake.profiles[i] != want[i] {
+			t.Errorf("request %d: expected %+v, got %+v", i, want[i], fake.profiles[i])
+		}
+		if fake.auth[i] != "Bearer xoxp-test" {
+			t.Errorf("request %d: unexpected Authorization %q", i, fake.auth[i])
+		}
+	}
+}
+
+func TestSetStatusError(t *testing.T) {
+	fake, client := newFake(t)
+	fake.reply = `{"ok":false,"error":"invalid_auth"}`
+
+	err := client.SetStatus(context.Background(), "x", "", time.Time{})
+	if err == nil || err.Error() != "setting Slack status: invalid_auth" {
+		t.Errorf("expected invalid_auth error, got %v", err)
+	}
+}
+
+func TestApply(t *testing.T) {
+	now := time.Date(2026, 1, 9, 9, 0, 0, 0, time.UTC)
+	standup := &calendar.MeetingInfo{Summary: "Standup", Start: now, End: now.Add(15 * time.Minute)}
+	review := &calendar.MeetingInfo{Summary: "Review", Start: now.Add(15 * time.Minute), End: now.Add(time.Hour)}
+
+	tests := []struct {
+		name        string
+		transitions []transition.Transition
+		want        []profile
+	}{
+		{
+			name:        "meeting starts",
+			transitions: []transition.Transition{{Kind: transition.MeetingStarted, Meeting: standup}},
+			want:        []profile{{StatusText: "Standup until 09:15", StatusEmoji: ":spiral_calendar_pad:", StatusExpiration: standup.End.Unix()}},
+		},
+		{
+			name:        "meeting ends",
+			transitions: []transition.Transition{{Kind: transition.MeetingEnded, Meeting: standup}},
+			want:        []profile{{}},
+		},
+		{
+			name: "meeting ends into the next one",
+			transitions: []transition.Transition{
+				{Kind: transition.MeetingEnded, Meeting: standup},
+				{Kind: transition.MeetingStarted, Meeting: review},
+			},
+			want: []profile{{StatusText: "Review until 10:00", StatusEmoji: ":spiral_calendar_pad:", StatusExpiration: review.End.Unix()}},
+		},
+		{
+			name: "last meeting ends",
+			transitions: []transition.Transition{
+				{Kind: transition.MeetingEnded, Meeting: review},
+				{Kind: transition.DayFree},
+			},
+			want: []profile{{}},
+		},
+		{
+			name:        "soon is ignored",
+			transitions: []transition.Transition{{Kind: transition.MeetingSoon, Meeting: review}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, client := newFake(t)
+			sync, err := NewSync(client, "{{.Summary}} until {{.End.Format \"15:04\"}}", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := sync.Apply(context.Background(), tt.transitions); err != nil {
+				t.Fatalf("Apply failed: %v", err)
+			}
+			if len(fake.profiles) != len(tt.want) {
+				t.Fatalf("expected %d requests, got %+v", len(tt.want), fake.profiles)
+			}
+			for i := range tt.want {
+				if fake.profiles[i] != tt.want[i] {
+					t.Errorf("request %d: expected %+v, got %+v", i, tt.want[i], fake.profiles[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNewSyncInvalidTemplate(t *testing.T) {
+	if _, err := NewSync(NewClient("", "x"), "{{.Summary", ""); err == nil {
+		t.Error("expected an error for an invalid template")
+	}
+}
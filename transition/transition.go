@@ -0,0 +1,251 @@
+package transition
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"next-meeting/calendar"
+	"next-meeting/filelock"
+	"next-meeting/secure"
+)
+
+const stateFileName = "next-meeting-state.json"
+
+// Kind names a change of the meeting status
+type Kind string
+
+// Transitions that are detected
+const (
+	MeetingSoon      Kind = "meeting-soon"      // The next meeting starts within the soon threshold
+	MeetingStarted   Kind = "meeting-started"   // A meeting became the current one
+	MeetingEnded     Kind = "meeting-ended"     // The current meeting is over
+	MeetingCancelled Kind = "meeting-cancelled" // A meeting that had not started yet was removed from today
+	MeetingMoved     Kind = "meeting-moved"     // A meeting that had not started yet was rescheduled within today
+	DayFree          Kind = "day-free"          // There are no more meetings today
+	Offline          Kind = "offline"           // The calendar could not be reached
+)
+
+// Kinds lists every transition kind
+var Kinds = []Kind{MeetingSoon, MeetingStarted, MeetingEnded, MeetingCancelled, MeetingMoved, DayFree, Offline}
+
+// Transition is a change detected between two observations
+type Transition struct {
+	Kind     Kind
+	Meeting  *calendar.MeetingInfo // Meeting the transition is about, nil for day-free and offline
+	Previous *calendar.MeetingInfo // The meeting before it was moved, only for meeting-moved
+	At       time.Time
+}
+
+// key identifies the transition so it fires only once
+func (t Transition) key() string {
+	return string(t.Kind) + "|" + meetingKey(t.Meeting)
+}
+
+// State is what was observed last, persisted between runs
+type State struct {
+	Day       string                  `json:"day"` // Local date of the observation (YYYY-MM-DD)
+	Current   *calendar.MeetingInfo   `json:"current"`
+	Soon      *calendar.MeetingInfo   `json:"soon"`
+	Upcoming  bool                    `json:"upcoming"`  // There were meetings left today
+	Scheduled []*calendar.MeetingInfo `json:"scheduled"` // Meetings with an ID that had not started yet
+	Offline   bool                    `json:"offline"`
+	Fired     []string                `json:"fired"` // Transitions already fired today
+}
+
+// Observe returns the state for a status observed at now. A meeting starting
+// within soon counts as the soon meeting. When offline, status is ignored and
+// the meetings of prev are kept so nothing seems to start or end.
+func Observe(prev *State, status *calendar.MeetingStatus, offline bool, now time.Time, soon time.Duration) *State {
+	day := now.Format(time.DateOnly)
+	next := &State{Day: day, Offline: offline}
+	if prev.Day == day {
+		next.Fired = slices.Clone(prev.Fired)
+	}
+
+	if offline || status == nil {
+		next.Current, next.Soon, next.Upcoming, next.Scheduled = prev.Current, prev.Soon, prev.Upcoming, prev.Scheduled
+		return next
+	}
+
+	next.Current = status.CurrentMeeting
+	next.Upcoming = status.CurrentMeeting != nil || status.NextMeeting != nil
+	for _, meeting := range status.Upcoming {
+		if meeting.ID != "" {
+			next.Scheduled = append(next.Scheduled, meeting)
+		}
+	}
+	if meeting := status.NextMeeting; meeting != nil && meeting.Start.Sub(now) <= soon {
+		next.Soon = meeting
+	}
+	return next
+}
+
+// Detect returns the transitions from prev to next that have not fired yet
+// and records them as fired in next
+func Detect(prev, next *State, now time.Time) []Transition {
+	var candidates []Transition
+
+	if next.Offline {
+		if !prev.Offline {
+			candidates = append(candidates, Transition{Kind: Offline, At: now})
+		}
+	} else {
+		if prev.Current != nil && !sameMeeting(prev.Current, next.Current) {
+			candidates = append(candidates, Transition{Kind: MeetingEnded, Meeting: prev.Current, At: now})
+		}
+		if next.Current != nil && !sameMeeting(prev.Current, next.Current) {
+			candidates = append(candidates, Transition{Kind: MeetingStarted, Meeting: next.Current, At: now})
+		}
+		candidates = append(candidates, rescheduled(prev, next, now)...)
+		if next.Soon != nil && !sameMeeting(prev.Soon, next.Soon) {
+			candidates = append(candidates, Transition{Kind: MeetingSoon, Meeting: next.Soon, At: now})
+		}
+		if prev.Upcoming && !next.Upcoming {
+			candidates = append(candidates, Transition{Kind: DayFree, At: now})
+		}
+	}
+
+	var transitions []Transition
+	for _, t := range candidates {
+		// Offline and day-free may happen again, meetings only start once
+		if t.Meeting != nil && slices.Contains(next.Fired, t.key()) {
+			continue
+		}
+		if t.Meeting != nil {
+			next.Fired = append(next.Fired, t.key())
+		}
+		transitions = append(transitions, t)
+	}
+	return transitions
+}
+
+// rescheduled returns the meetings scheduled in prev that were cancelled or
+// moved by next. A meeting that is gone because it started is neither. Only
+// today's events are known, so a meeting moved to another day, or one that no
+// longer passes the event filters, looks the same as a cancelled one.
+func rescheduled(prev, next *State, now time.Time) []Transition {
+	var transitions []Transition
+	for _, before := range prev.Scheduled {
+		after := findMeeting(next.Scheduled, before.ID)
+		if after == nil && next.Current != nil && next.Current.ID == before.ID {
+			after = next.Current
+		}
+		switch {
+		case after == nil && before.Start.After(now):
+			transitions = append(transitions, Transition{Kind: MeetingCancelled, Meeting: before, At: now})
+		case after != nil && (!after.Start.Equal(before.Start) || !after.End.Equal(before.End)):
+			transitions = append(transitions, Transition{Kind: MeetingMoved, Meeting: after, Previous: before, At: now})
+		}
+	}
+	return transitions
+}
+
+func findMeeting(meetings []*calendar.MeetingInfo, id string) *calendar.MeetingInfo {
+	for _, meeting := range meetings {
+		if meeting.ID == id {
+			return meeting
+		}
+	}
+	return nil
+}
+
+func sameMeeting(a, b *calendar.MeetingInfo) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return meetingKey(a) == meetingKey(b)
+}
+
+func meetingKey(meeting *calendar.MeetingInfo) string {
+	if meeting == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s|%s|%s", meeting.Summary, meeting.Start.Format(time.RFC3339), meeting.End.Format(time.RFC3339))
+}
+
+// encrypt controls whether the state file is encrypted at rest
+var encrypt bool
+
+// SetEncryption enables or disables encryption of the state file
+func SetEncryption(enabled bool) {
+	encrypt = enabled
+}
+
+// GetPath returns the path of the state file
+func GetPath() string {
+	return filepath.Join(os.TempDir(), stateFileName)
+}
+
+// Load reads the state saved at path. A missing or unreadable file gives an
+// empty state, as if nothing had been observed yet.
+func Load(path string) *State {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return &State{}
+	}
+	if secure.IsSealed(data) {
+		if data, err = secure.Open(data); err != nil {
+			return &State{}
+		}
+	}
+
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return &State{}
+	}
+	return &state
+}
+
+// Save writes state to path
+func Save(path string, state *State) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if encrypt {
+		if data, err = secure.Seal(data); err != nil {
+			return fmt.Errorf("failed to encrypt state: %w", err)
+		}
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// Tracker detects transitions between successive observations and persists
+// what it saw, so transitions are detected across runs
+type Tracker struct {
+	path string
+	soon time.Duration
+}
+
+// NewTracker creates a tracker that continues from the state saved at path
+func NewTracker(path string, soon time.Duration) *Tracker {
+	return &Tracker{path: path, soon: soon}
+}
+
+// Observe records the status at now and returns the transitions since the
+// previous observation. status is ignored when offline. The saved state is
+// locked while it is compared and updated, so when several processes observe
+// at once each transition is returned to only one of them.
+func (t *Tracker) Observe(status *calendar.MeetingStatus, offline bool, now time.Time) ([]Transition, error) {
+	unlock, err := filelock.Lock(t.path)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	prev := Load(t.path)
+	next := Observe(prev, status, offline, now, t.soon)
+	transitions := Detect(prev, next, now)
+
+	changed := len(transitions) > 0 || prev.Day != next.Day || prev.Offline != next.Offline ||
+		prev.Upcoming != next.Upcoming || !sameMeeting(prev.Current, next.Current) || !sameMeeting(prev.Soon, next.Soon) ||
+		!slices.EqualFunc(prev.Scheduled, next.Scheduled, sameMeeting)
+	if !changed {
+		return transitions, nil
+	}
+	return transitions, Save(t.path, next)
+}
@@ -0,0 +1,269 @@
+package transition
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zalando/go-keyring"
+
+	"next-meeting/calendar"
+)
+
+func TestDetect(t *testing.T) {
+	base := time.Date(2026, 1, 9, 9, 0, 0, 0, time.UTC)
+	soon := 10 * time.Minute
+	standup := &calendar.MeetingInfo{Summary: "Standup", Start: base.Add(30 * time.Minute), End: base.Add(45 * time.Minute)}
+	review := &calendar.MeetingInfo{Summary: "Review", Start: base.Add(45 * time.Minute), End: base.Add(90 * time.Minute)}
+	events := []*calendar.MeetingInfo{standup, review}
+
+	tests := []struct {
+		name    string
+		at      time.Duration
+		offline bool
+		want    []Kind
+	}{
+		{name: "free with meetings ahead", at: 0},
+		{name: "still free", at: 10 * time.Minute},
+		{name: "standup is soon", at: 25 * time.Minute, want: []Kind{MeetingSoon}},
+		{name: "standup still soon", at: 28 * time.Minute},
+		{name: "standup starts, review is soon", at: 40 * time.Minute, want: []Kind{MeetingStarted, MeetingSoon}},
+		{name: "calendar offline", at: 42 * time.Minute, offline: true, want: []Kind{Offline}},
+		{name: "still offline", at: 43 * time.Minute, offline: true},
+		{name: "back online in the same meeting", at: 44 * time.Minute},
+		{name: "standup ends into review", at: 50 * time.Minute, want: []Kind{MeetingEnded, MeetingStarted}},
+		{name: "review ends, day is free", at: 2 * time.Hour, want: []Kind{MeetingEnded, DayFree}},
+		{name: "still free", at: 3 * time.Hour},
+	}
+
+	state := &State{}
+	for _, tt := range tests {
+		now := base.Add(tt.at)
+		next := Observe(state, calendar.GetMeetingStatus(events, now), tt.offline, now, soon)
+		got := Detect(state, next, now)
+		state = next
+
+		var kinds []Kind
+		for _, transition := range got {
+			kinds = append(kinds, transition.Kind)
+		}
+		if strings.Join(kindStrings(kinds), ",") != strings.Join(kindStrings(tt.want), ",") {
+			t.Errorf("%s: got %v, want %v", tt.name, kinds, tt.want)
+		}
+	}
+}
+
+func kindStrings(kinds []Kind) []string {
+	var s []string
+	for _, kind := range kinds {
+		s = append(s, string(kind))
+	}
+	return s
+}
+
+func TestDetectMeetingDetails(t *testing.T) {
+	now := time.Date(2026, 1, 9, 9, 0, 0, 0, time.UTC)
+	meeting := &calendar.MeetingInfo{Summary: "Standup", Start: now.Add(-time.Minute), End: now.Add(time.Hour)}
+
+	prev := &State{Day: now.Format(time.DateOnly), Upcoming: true}
+	next := Observe(prev, calendar.GetMeetingStatus([]*calendar.MeetingInfo{meeting}, now), false, now, 0)
+	got := Detect(prev, next, now)
+	if len(got) != 1 || got[0].Kind != MeetingStarted || got[0].Meeting != meeting || !got[0].At.Equal(now) {
+		t.Fatalf("unexpected transitions: %+v", got)
+	}
+
+	// A meeting that ends while nothing is observed still ends once
+	later := now.Add(2 * time.Hour)
+	ended := Detect(next, Observe(next, calendar.GetMeetingStatus([]*calendar.MeetingInfo{meeting}, later), false, later, 0), later)
+	if len(ended) != 2 || ended[0].Kind != MeetingEnded || ended[0].Meeting.Summary != "Standup" || ended[1].Kind != DayFree {
+		t.Fatalf("unexpected transitions: %+v", ended)
+	}
+}
+
+func TestDetectFiresOnce(t *testing.T) {
+	now := time.Date(2026, 1, 9, 9, 0, 0, 0, time.UTC)
+	meeting := &calendar.MeetingInfo{Summary: "Standup", Start: now.Add(-time.Minute), End: now.Add(time.Hour)}
+	status := calendar.GetMeetingStatus([]*calendar.MeetingInfo{meeting}, now)
+
+	state := Observe(&State{}, status, false, now, 0)
+	if got := Detect(&State{}, state, now); len(got) != 1 {
+		t.Fatalf("expected the meeting to start, got %+v", got)
+	}
+
+	// Losing the meeting, e.g. because a refresh briefly returned nothing,
+	// and seeing it again does not start it twice
+	empty := Observe(state, &calendar.MeetingStatus{}, false, now, 0)
+	Detect(state, empty, now)
+	again := Observe(empty, status, false, now, 0)
+	if got := Detect(empty, again, now); len(got) != 0 {
+		t.Errorf("expected no transitions when the meeting reappears, got %+v", got)
+	}
+
+	// Fired transitions are forgotten the next day
+	tomorrow := now.AddDate(0, 0, 1)
+	next := Observe(again, &calendar.MeetingStatus{}, false, tomorrow, 0)
+	if len(next.Fired) != 0 {
+		t.Errorf("expected fired transitions to be reset on a new day, got %v", next.Fired)
+	}
+}
+
+func TestDetectRescheduled(t *testing.T) {
+	now := time.Date(2026, 1, 9, 9, 0, 0, 0, time.UTC)
+	standup := &calendar.MeetingInfo{ID: "a", Summary: "Standup", Start: now.Add(time.Hour), End: now.Add(75 * time.Minute)}
+	review := &calendar.MeetingInfo{ID: "b", Summary: "Review", Start: now.Add(2 * time.Hour), End: now.Add(3 * time.Hour)}
+	moved := &calendar.MeetingInfo{ID: "a", Summary: "Standup", Start: now.Add(90 * time.Minute), End: now.Add(105 * time.Minute)}
+	noID := &calendar.MeetingInfo{Summary: "Cached", Start: now.Add(4 * time.Hour), End: now.Add(5 * time.Hour)}
+
+	observe := func(prev *State, at time.Time, events ...*calendar.MeetingInfo) (*State, []Transition) {
+		next := Observe(prev, calendar.GetMeetingStatus(events, at), false, at, 0)
+		return next, Detect(prev, next, at)
+	}
+
+	state, _ := observe(&State{}, now, standup, review, noID)
+
+	state, got := observe(state, now, moved, review, noID)
+	if len(got) != 1 || got[0].Kind != MeetingMoved || got[0].Meeting != moved || got[0].Previous != standup {
+		t.Fatalf("expected standup to be moved, got %+v", got)
+	}
+
+	state, got = observe(state, now, moved, noID)
+	if len(got) != 1 || got[0].Kind != MeetingCancelled || got[0].Meeting != review {
+		t.Fatalf("expected review to be cancelled, got %+v", got)
+	}
+
+	// Moving a meeting to another day takes it out of today's events, which
+	// can't be told apart from cancelling it
+	oneOnOne := &calendar.MeetingInfo{ID: "c", Summary: "1:1", Start: now.Add(5 * time.Hour), End: now.Add(6 * time.Hour)}
+	state, _ = observe(state, now, moved, noID, oneOnOne)
+	state, got = observe(state, now, moved, noID)
+	if len(got) != 1 || got[0].Kind != MeetingCancelled || got[0].Meeting != oneOnOne {
+		t.Fatalf("expected the meeting moved to tomorrow to be reported as cancelled, got %+v", got)
+	}
+
+	// Meetings without an ID are never reported as cancelled
+	state, got = observe(state, now, moved)
+	if len(got) != 0 {
+		t.Fatalf("expected no transitions, got %+v", got)
+	}
+
+	// A meeting that starts is not cancelled
+	start := moved.Start.Add(time.Minute)
+	if _, got = observe(state, start, moved); len(got) != 1 || got[0].Kind != MeetingStarted {
+		t.Fatalf("expected only the start, got %+v", got)
+	}
+	end := moved.End.Add(time.Minute)
+	if _, got = observe(state, end); len(got) != 1 || got[0].Kind != DayFree {
+		t.Fatalf("expected only the day to be free, got %+v", got)
+	}
+}
+
+func TestTrackerPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), stateFileName)
+	base := time.Date(2026, 1, 9, 9, 0, 0, 0, time.UTC)
+	meeting := &calendar.MeetingInfo{Summary: "Standup", Start: base.Add(5 * time.Minute), End: base.Add(20 * time.Minute)}
+	events := []*calendar.MeetingInfo{meeting}
+
+	observe := func(at time.Duration) []Transition {
+		t.Helper()
+		now := base.Add(at)
+		// Every observation is a new run
+		got, err := NewTracker(path, 10*time.Minute).Observe(calendar.GetMeetingStatus(events, now), false, now)
+		if err != nil {
+			t.Fatalf("Observe failed: %v", err)
+		}
+		return got
+	}
+
+	if got := observe(0); len(got) != 1 || got[0].Kind != MeetingSoon {
+		t.Fatalf("expected meeting-soon, got %+v", got)
+	}
+	if got := observe(time.Minute); len(got) != 0 {
+		t.Fatalf("expected no transitions on the next run, got %+v", got)
+	}
+	if got := observe(10 * time.Minute); len(got) != 1 || got[0].Kind != MeetingStarted {
+		t.Fatalf("expected meeting-started, got %+v", got)
+	}
+	if got := observe(11 * time.Minute); len(got) != 0 {
+		t.Fatalf("expected no transitions on the next run, got %+v", got)
+	}
+	if got := observe(30 * time.Minute); len(got) != 2 {
+		t.Fatalf("expected meeting-ended and day-free, got %+v", got)
+	}
+}
+
+func TestTrackersSharingState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), stateFileName)
+	now := time.Date(2026, 1, 9, 9, 0, 0, 0, time.UTC)
+	events := []*calendar.MeetingInfo{{Summary: "Standup", Start: now.Add(-time.Minute), End: now.Add(15 * time.Minute)}}
+	status := calendar.GetMeetingStatus(events, now)
+
+	// Both are created before either observes, like runs started at the same time
+	first, second := NewTracker(path, 10*time.Minute), NewTracker(path, 10*time.Minute)
+	if got, err := first.Observe(status, false, now); err != nil || len(got) != 1 || got[0].Kind != MeetingStarted {
+		t.Fatalf("expected meeting-started, got %+v (%v)", got, err)
+	}
+	if got, err := second.Observe(status, false, now); err != nil || len(got) != 0 {
+		t.Fatalf("expected nothing left to fire, got %+v (%v)", got, err)
+	}
+
+	// Concurrent observations fire each transition once between them
+	later := now.Add(20 * time.Minute)
+	status = calendar.GetMeetingStatus(events, later)
+	results := make(chan int)
+	for range 4 {
+		go func() {
+			got, err := NewTracker(path, 10*time.Minute).Observe(status, false, later)
+			if err != nil {
+				t.Error(err)
+			}
+			results <- len(got)
+		}()
+	}
+	total := 0
+	for range 4 {
+		total += <-results
+	}
+	if total != 2 {
+		t.Errorf("expected meeting-ended and day-free once, got %d transitions", total)
+	}
+}
+
+func TestLoadMissingOrCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), stateFileName)
+	if state := Load(path); state == nil || state.Current != nil {
+		t.Fatalf("expected empty state for a missing file, got %+v", state)
+	}
+
+	if err := os.WriteFile(path, []byte("{not json"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if state := Load(path); state == nil || state.Current != nil {
+		t.Fatalf("expected empty state for a corrupt file, got %+v", state)
+	}
+}
+
+func TestSaveEncrypted(t *testing.T) {
+	keyring.MockInit()
+	SetEncryption(true)
+	defer SetEncryption(false)
+
+	path := filepath.Join(t.TempDir(), stateFileName)
+	now := time.Date(2026, 1, 9, 9, 0, 0, 0, time.UTC)
+	state := &State{Day: "2026-01-09", Current: &calendar.MeetingInfo{Summary: "Confidential Call", Start: now, End: now.Add(time.Hour)}}
+	if err := Save(path, state); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "Confidential") {
+		t.Fatalf("expected state to be encrypted, got %q", data)
+	}
+	if loaded := Load(path); loaded.Current == nil || loaded.Current.Summary != "Confidential Call" {
+		t.Errorf("expected encrypted state to load, got %+v", loaded)
+	}
+}
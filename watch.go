@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"next-meeting/calendar"
+	"next-meeting/config"
+	"next-meeting/monitor"
+	"next-meeting/output"
+)
+
+// runWatch writes the status to out every interval from the events in memory
+// until ctx is cancelled. On a terminal the line is redrawn in place; otherwise
+// a new line is printed whenever the output changes.
+func runWatch(ctx context.Context, out *os.File, mon *monitor.Monitor, formatter *output.Formatter, cfg *config.Config, interval time.Duration) error {
+	_ = mon.Refresh(ctx)
+	go mon.Run(ctx)
+
+	tty := isTerminal(out)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := ""
+	for {
+		text, err := watchText(mon, formatter, cfg)
+		if err != nil {
+			return err
+		}
+
+		if tty {
+			if _, err := fmt.Fprintf(out, "\r\033[K%s", text); err != nil {
+				return err
+			}
+		} else if text != last {
+			if _, err := fmt.Fprintln(out, text); err != nil {
+				return err
+			}
+		}
+		last = text
+
+		select {
+		case <-ctx.Done():
+			if tty {
+				fmt.Fprintln(out)
+			}
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// watchText renders the current status from memory
+func watchText(mon *monitor.Monitor, formatter *output.Formatter, cfg *config.Config) (string, error) {
+	if err := mon.Err(); err != nil && mon.Events() == nil {
+		if isNetworkError(err) {
+			return renderOffline(cfg), nil
+		}
+		return "", fmt.Errorf("loading events: %w", err)
+	}
+
+	now := mon.Now()
+	events := mon.Events()
+	return renderStatus(formatter, cfg, events, calendar.GetMeetingStatus(events, now), now, mon.FetchedAt())
+}
+
+// isTerminal reports whether f is an interactive terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
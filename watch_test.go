@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"next-meeting/calendar"
+	"next-meeting/clock"
+	"next-meeting/config"
+	"next-meeting/monitor"
+	"next-meeting/output"
+)
+
+func TestRunWatchPrintsChanges(t *testing.T) {
+	now := time.Date(2026, 1, 9, 14, 30, 0, 0, time.UTC)
+	var mu sync.Mutex
+	events := []*calendar.MeetingInfo{
+		{Summary: "Standup", Start: now.Add(-5 * time.Minute), End: now.Add(10 * time.Minute)},
+	}
+	fetch := func(ctx context.Context, force bool) ([]*calendar.MeetingInfo, time.Time, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return events, now, nil
+	}
+	mon := monitor.New(fetch, clock.Fixed(now), time.Hour)
+	formatter, err := output.NewFormatter(output.DefaultTemplates())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A regular file is not a terminal, so a line is printed per change
+	path := filepath.Join(t.TempDir(), "out")
+	out, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- runWatch(ctx, out, mon, formatter, config.Default(), time.Millisecond) }()
+
+	waitForLines := func(n int) []string {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			data, _ := os.ReadFile(path)
+			lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+			if len(data) > 0 && len(lines) >= n {
+				return lines
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for %d lines, got %q", n, data)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	waitForLines(1)
+	mu.Lock()
+	events = nil
+	mu.Unlock()
+	if err := mon.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+	waitForLines(2)
+	time.Sleep(20 * time.Millisecond) // Several more ticks without changes
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("runWatch failed: %v", err)
+	}
+
+	want := []string{"🔴 Standup (10m left)", output.DefaultEmptyTemplate}
+	lines := waitForLines(2)
+	if len(lines) != len(want) {
+		t.Fatalf("expected %q, got %q", want, lines)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("line %d = %q, want %q", i, lines[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"next-meeting/config"
+	"next-meeting/transition"
+	"next-meeting/webhook"
+)
+
+// webhookFlushTimeout bounds how long delivering webhooks may hold up the status line
+const webhookFlushTimeout = 5 * time.Second
+
+// deliverWebhooks queues transitions for the configured webhooks, then sends
+// whatever is due in the outbox, including earlier failed deliveries
+func deliverWebhooks(ctx context.Context, cfg *config.Config, transitions []transition.Transition, now time.Time) []error {
+	var errs []error
+	outbox := webhook.NewOutbox(webhook.GetPath())
+	for _, hook := range cfg.Webhooks {
+		if len(transitions) == 0 {
+			break
+		}
+		endpoint, err := webhook.NewEndpoint(hook.URL, hook.Secret, hook.Events, hook.Template)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, t := range transitions {
+			if !endpoint.Wants(t.Kind) {
+				continue
+			}
+			d, err := endpoint.NewDelivery(t)
+			if err == nil {
+				err = outbox.Add(d)
+			}
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, webhookFlushTimeout)
+	defer cancel()
+	return append(errs, outbox.Flush(ctx, now)...)
+}
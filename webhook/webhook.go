@@ -0,0 +1,359 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"next-meeting/calendar"
+	"next-meeting/filelock"
+	"next-meeting/hooks"
+	"next-meeting/output"
+	"next-meeting/secure"
+	"next-meeting/transition"
+)
+
+const outboxDirName = "next-meeting-outbox"
+
+// Headers sent with every delivery
+const (
+	EventHeader     = "X-Next-Meeting-Event"
+	DeliveryHeader  = "X-Next-Meeting-Delivery"
+	SignatureHeader = "X-Next-Meeting-Signature" // "sha256=" and the hex HMAC of the body
+)
+
+const (
+	requestTimeout = 10 * time.Second
+	lockRetry      = 50 * time.Millisecond // How often to check whether another process finished flushing
+
+	// Failed deliveries are retried after initialBackoff, doubling up to
+	// maxBackoff, and dropped once they are older than maxAge
+	initialBackoff = 30 * time.Second
+	maxBackoff     = 30 * time.Minute
+	maxAge         = 24 * time.Hour
+)
+
+// encrypt controls whether queued deliveries are encrypted at rest
+var encrypt bool
+
+// SetEncryption enables or disables encryption of queued deliveries
+func SetEncryption(enabled bool) {
+	encrypt = enabled
+}
+
+// GetPath returns the path of the outbox directory
+func GetPath() string {
+	return filepath.Join(os.TempDir(), outboxDirName)
+}
+
+// TemplateData is what payload templates are executed with
+type TemplateData struct {
+	Event    transition.Kind
+	Time     time.Time
+	Meeting  *calendar.MeetingInfo // nil for day-free and offline
+	Previous *calendar.MeetingInfo // The meeting before it was moved
+}
+
+// templateFuncs are the output template functions plus json, which renders
+// a value as JSON so strings are quoted and escaped
+var templateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// Endpoint is a URL transitions are posted to
+type Endpoint struct {
+	URL      string
+	secret   string
+	events   []transition.Kind
+	template *template.Template
+}
+
+// NewEndpoint creates an endpoint receiving the given events, or all of them
+// if none are given. Bodies are signed with secret unless it is empty, and
+// rendered with tmpl instead of the default JSON payload if it is set.
+func NewEndpoint(url, secret string, events []string, tmpl string) (*Endpoint, error) {
+	e := &Endpoint{URL: url, secret: secret}
+	for _, event := range events {
+		kind := transition.Kind(event)
+		if !slices.Contains(transition.Kinds, kind) {
+			return nil, fmt.Errorf("unknown webhook event %q", event)
+		}
+		e.events = append(e.events, kind)
+	}
+	if tmpl != "" {
+		parsed, err := template.New("webhook").Funcs(output.Funcs).Funcs(templateFuncs).Parse(tmpl)
+		if err != nil {
+			return nil, fmt.Errorf("invalid webhook template: %w", err)
+		}
+		e.template = parsed
+	}
+	return e, nil
+}
+
+// Wants reports whether the endpoint receives transitions of kind
+func (e *Endpoint) Wants(kind transition.Kind) bool {
+	return len(e.events) == 0 || slices.Contains(e.events, kind)
+}
+
+// Body renders the request body for t
+func (e *Endpoint) Body(t transition.Transition) ([]byte, error) {
+	if e.template == nil {
+		return json.Marshal(hooks.NewPayload(t))
+	}
+	var sb strings.Builder
+	data := TemplateData{Event: t.Kind, Time: t.At, Meeting: t.Meeting, Previous: t.Previous}
+	if err := e.template.Execute(&sb, data); err != nil {
+		return nil, fmt.Errorf("failed to render webhook payload: %w", err)
+	}
+	return []byte(sb.String()), nil
+}
+
+// NewDelivery prepares the delivery of t to the endpoint
+func (e *Endpoint) NewDelivery(t transition.Transition) (*Delivery, error) {
+	body, err := e.Body(t)
+	if err != nil {
+		return nil, err
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	d := &Delivery{
+		ID:      hex.EncodeToString(id),
+		URL:     e.URL,
+		Event:   t.Kind,
+		Body:    string(body),
+		Created: t.At,
+	}
+	if e.secret != "" {
+		d.Signature = Sign(e.secret, body)
+	}
+	return d, nil
+}
+
+// Sign returns the signature header value for body
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Delivery is a request waiting in the outbox. It is signed when queued, so
+// the secret is never written to disk.
+type Delivery struct {
+	ID          string          `json:"id"`
+	URL         string          `json:"url"`
+	Event       transition.Kind `json:"event"`
+	Body        string          `json:"body"`
+	Signature   string          `json:"signature,omitempty"`
+	Created     time.Time       `json:"created"`
+	Attempts    int             `json:"attempts"`
+	NextAttempt time.Time       `json:"next_attempt"`
+	LastError   string          `json:"last_error,omitempty"`
+}
+
+// permanentError is a failure retrying won't fix
+type permanentError struct{ error }
+
+// Outbox keeps deliveries on disk until they succeed, so none are lost
+// while offline
+type Outbox struct {
+	dir    string
+	client *http.Client
+}
+
+// NewOutbox creates an outbox stored in dir
+func NewOutbox(dir string) *Outbox {
+	return &Outbox{dir: dir, client: &http.Client{Timeout: requestTimeout}}
+}
+
+// Add queues d
+func (o *Outbox) Add(d *Delivery) error {
+	if err := os.MkdirAll(o.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create outbox: %w", err)
+	}
+	return o.save(d)
+}
+
+func (o *Outbox) path(d *Delivery) string {
+	return filepath.Join(o.dir, d.ID+".json")
+}
+
+func (o *Outbox) save(d *Delivery) error {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	if encrypt {
+		if data, err = secure.Seal(data); err != nil {
+			return fmt.Errorf("failed to encrypt delivery: %w", err)
+		}
+	}
+	return os.WriteFile(o.path(d), data, 0600)
+}
+
+// Pending returns the queued deliveries, oldest first
+func (o *Outbox) Pending() ([]*Delivery, error) {
+	entries, err := os.ReadDir(o.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var deliveries []*Delivery
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(o.dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		if secure.IsSealed(data) {
+			if data, err = secure.Open(data); err != nil {
+				continue
+			}
+		}
+		var d Delivery
+		if err := json.Unmarshal(data, &d); err != nil {
+			continue
+		}
+		deliveries = append(deliveries, &d)
+	}
+
+	sort.SliceStable(deliveries, func(i, j int) bool {
+		return deliveries[i].Created.Before(deliveries[j].Created)
+	})
+	return deliveries, nil
+}
+
+// Flush sends the deliveries that are due at now. Failed deliveries are kept
+// for a retry with backoff; later deliveries to the same URL wait for them,
+// so each endpoint receives transitions in order. Only one process flushes at
+// a time; if another one doesn't finish before ctx is done, nothing is sent
+// and the deliveries wait for the next flush. The returned errors are for
+// deliveries that were given up on.
+func (o *Outbox) Flush(ctx context.Context, now time.Time) []error {
+	unlock, err := o.lock(ctx)
+	if err != nil {
+		return []error{err}
+	}
+	if unlock == nil {
+		return nil
+	}
+	defer unlock()
+
+	deliveries, err := o.Pending()
+	if err != nil {
+		return []error{err}
+	}
+
+	var errs []error
+	blocked := map[string]bool{}
+	for _, d := range deliveries {
+		if blocked[d.URL] {
+			continue
+		}
+		if now.Before(d.NextAttempt) {
+			blocked[d.URL] = true
+			continue
+		}
+
+		err := o.send(ctx, d)
+		if err == nil {
+			_ = os.Remove(o.path(d))
+			continue
+		}
+
+		if errors.As(err, new(permanentError)) || now.Sub(d.Created) >= maxAge {
+			_ = os.Remove(o.path(d))
+			errs = append(errs, fmt.Errorf("dropped %s webhook to %s: %w", d.Event, d.URL, err))
+			continue
+		}
+
+		d.Attempts++
+		d.NextAttempt = now.Add(backoff(d.Attempts))
+		d.LastError = err.Error()
+		if err := o.save(d); err != nil {
+			errs = append(errs, err)
+		}
+		blocked[d.URL] = true
+	}
+	return errs
+}
+
+// lock waits until no other process is flushing the outbox and locks it. It
+// returns a nil unlock function if ctx is done first.
+func (o *Outbox) lock(ctx context.Context) (func(), error) {
+	ticker := time.NewTicker(lockRetry)
+	defer ticker.Stop()
+	for {
+		unlock, ok, err := filelock.TryLock(o.dir)
+		if err != nil || ok {
+			return unlock, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// send posts d once
+func (o *Outbox) send(ctx context.Context, d *Delivery) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, strings.NewReader(d.Body))
+	if err != nil {
+		return permanentError{err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(d.Event))
+	req.Header.Set(DeliveryHeader, d.ID)
+	if d.Signature != "" {
+		req.Header.Set(SignatureHeader, d.Signature)
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode < 500 && resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests:
+		return permanentError{errors.New(resp.Status)}
+	default:
+		return errors.New(resp.Status)
+	}
+}
+
+// backoff returns how long to wait before retrying after the given number
+// of failed attempts
+func backoff(attempts int) time.Duration {
+	wait := initialBackoff
+	for i := 1; i < attempts && wait < maxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, maxBackoff)
+}
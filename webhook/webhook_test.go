@@ -0,0 +1,283 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"next-meeting/calendar"
+	"next-meeting/filelock"
+	"next-meeting/hooks"
+	"next-meeting/transition"
+)
+
+// receiver records the requests it gets and answers with the queued statuses,
+// then 200
+type receiver struct {
+	delay time.Duration // How long to take to answer
+
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   []string
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	time.Sleep(r.delay)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, string(body))
+	if len(r.statuses) > 0 {
+		w.WriteHeader(r.statuses[0])
+		r.statuses = r.statuses[1:]
+	}
+}
+
+func (r *receiver) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.requests)
+}
+
+func newReceiver(t *testing.T, statuses ...int) (*receiver, string) {
+	t.Helper()
+	r := &receiver{statuses: statuses}
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	return r, srv.URL
+}
+
+var now = time.Date(2026, 1, 9, 9, 0, 0, 0, time.UTC)
+
+func started() transition.Transition {
+	meeting := &calendar.MeetingInfo{ID: "abc", Summary: `Standup "daily"`, Start: now, End: now.Add(15 * time.Minute)}
+	return transition.Transition{Kind: transition.MeetingStarted, Meeting: meeting, At: now}
+}
+
+func queue(t *testing.T, outbox *Outbox, e *Endpoint, tr transition.Transition) *Delivery {
+	t.Helper()
+	d, err := e.NewDelivery(tr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := outbox.Add(d); err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestFlushSignsDefaultPayload(t *testing.T) {
+	r, url := newReceiver(t)
+	e, err := NewEndpoint(url, "s3cret", nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	outbox := NewOutbox(t.TempDir())
+	d := queue(t, outbox, e, started())
+
+	if errs := outbox.Flush(context.Background(), now); len(errs) != 0 {
+		t.Fatalf("Flush failed: %v", errs)
+	}
+	if r.count() != 1 {
+		t.Fatalf("expected 1 request, got %d", r.count())
+	}
+
+	req, body := r.requests[0], r.bodies[0]
+	if got := req.Header.Get(SignatureHeader); got != Sign("s3cret", []byte(body)) {
+		t.Errorf("unexpected signature %q", got)
+	}
+	if req.Header.Get(EventHeader) != "meeting-started" || req.Header.Get(DeliveryHeader) != d.ID {
+		t.Errorf("unexpected headers: %v", req.Header)
+	}
+	var payload hooks.Payload
+	if err := json.Unmarshal([]byte(body), &payload); err != nil {
+		t.Fatalf("invalid payload: %v", err)
+	}
+	if payload.Event != transition.MeetingStarted || payload.Meeting == nil || payload.Meeting.ID != "abc" {
+		t.Errorf("unexpected payload: %s", body)
+	}
+
+	if pending, _ := outbox.Pending(); len(pending) != 0 {
+		t.Errorf("expected the outbox to be empty, got %d", len(pending))
+	}
+}
+
+func TestTemplatePayload(t *testing.T) {
+	e, err := NewEndpoint("http://example.com", "", nil, `{"text": {{json (printf "%s at %s" .Meeting.Summary (.Meeting.Start.Format "15:04"))}}, "event": "{{.Event}}"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := e.Body(started())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"text": "Standup \"daily\" at 09:00", "event": "meeting-started"}`; string(body) != want {
+		t.Errorf("expected %s, got %s", want, body)
+	}
+
+	d, err := e.NewDelivery(started())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Signature != "" {
+		t.Errorf("expected no signature without a secret, got %q", d.Signature)
+	}
+}
+
+func TestNewEndpointErrors(t *testing.T) {
+	if _, err := NewEndpoint("http://example.com", "", []string{"meeting-started", "lunch"}, ""); err == nil {
+		t.Error("expected an error for an unknown event")
+	}
+	if _, err := NewEndpoint("http://example.com", "", nil, "{{.Meeting"); err == nil {
+		t.Error("expected an error for an invalid template")
+	}
+
+	e, err := NewEndpoint("http://example.com", "", []string{"meeting-moved", "meeting-cancelled"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !e.Wants(transition.MeetingMoved) || e.Wants(transition.MeetingStarted) {
+		t.Error("expected the endpoint to only want the configured events")
+	}
+}
+
+func TestFlushRetriesWithBackoff(t *testing.T) {
+	r, url := newReceiver(t, http.StatusServiceUnavailable, http.StatusBadGateway)
+	e, _ := NewEndpoint(url, "", nil, "")
+	dir := t.TempDir()
+	first := queue(t, NewOutbox(dir), e, started())
+	ended := transition.Transition{Kind: transition.DayFree, At: now.Add(time.Second)}
+	queue(t, NewOutbox(dir), e, ended)
+
+	steps := []struct {
+		at       time.Duration
+		requests int
+		pending  int
+	}{
+		{at: 0, requests: 1, pending: 2},                                 // Fails, the later delivery waits
+		{at: initialBackoff - time.Second, requests: 1, pending: 2},      // Not due yet
+		{at: initialBackoff, requests: 2, pending: 2},                    // Fails again
+		{at: initialBackoff + 2*initialBackoff, requests: 4, pending: 0}, // Both delivered, in order
+	}
+	for _, step := range steps {
+		// A new outbox each time, as if run by separate invocations
+		if errs := NewOutbox(dir).Flush(context.Background(), now.Add(step.at)); len(errs) != 0 {
+			t.Fatalf("at %s: unexpected errors %v", step.at, errs)
+		}
+		pending, _ := NewOutbox(dir).Pending()
+		if r.count() != step.requests || len(pending) != step.pending {
+			t.Fatalf("at %s: expected %d requests and %d pending, got %d and %d", step.at, step.requests, step.pending, r.count(), len(pending))
+		}
+		if len(pending) > 0 && pending[0].ID == first.ID && pending[0].LastError == "" {
+			t.Errorf("at %s: expected the failure to be recorded", step.at)
+		}
+	}
+
+	if r.requests[2].Header.Get(DeliveryHeader) != first.ID || r.requests[3].Header.Get(EventHeader) != "day-free" {
+		t.Errorf("expected deliveries in order")
+	}
+}
+
+func TestConcurrentFlush(t *testing.T) {
+	r, url := newReceiver(t)
+	r.delay = 20 * time.Millisecond
+	e, _ := NewEndpoint(url, "", nil, "")
+	dir := t.TempDir()
+	for range 3 {
+		queue(t, NewOutbox(dir), e, started())
+	}
+
+	// Separate outboxes on one directory, as used by concurrent runs
+	var wg sync.WaitGroup
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if errs := NewOutbox(dir).Flush(context.Background(), now); len(errs) != 0 {
+				t.Errorf("Flush failed: %v", errs)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if r.count() != 3 {
+		t.Errorf("expected each delivery to be sent once, got %d requests", r.count())
+	}
+	if pending, _ := NewOutbox(dir).Pending(); len(pending) != 0 {
+		t.Errorf("expected the outbox to be empty, got %d", len(pending))
+	}
+}
+
+func TestFlushGivesUpWaiting(t *testing.T) {
+	_, url := newReceiver(t)
+	e, _ := NewEndpoint(url, "", nil, "")
+	dir := t.TempDir()
+	queue(t, NewOutbox(dir), e, started())
+
+	unlock, ok, err := filelock.TryLock(dir)
+	if err != nil || !ok {
+		t.Fatalf("failed to lock the outbox: %v", err)
+	}
+	defer unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if errs := NewOutbox(dir).Flush(ctx, now); len(errs) != 0 {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+	if pending, _ := NewOutbox(dir).Pending(); len(pending) != 1 {
+		t.Errorf("expected the delivery to wait for the next flush, got %d pending", len(pending))
+	}
+}
+
+func TestFlushDrops(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		at     time.Duration
+	}{
+		{name: "rejected by the endpoint", status: http.StatusBadRequest},
+		{name: "too old", status: http.StatusInternalServerError, at: maxAge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, url := newReceiver(t, tt.status)
+			e, _ := NewEndpoint(url, "", nil, "")
+			outbox := NewOutbox(t.TempDir())
+			queue(t, outbox, e, started())
+
+			if errs := outbox.Flush(context.Background(), now.Add(tt.at)); len(errs) != 1 {
+				t.Fatalf("expected the delivery to be dropped with an error, got %v", errs)
+			}
+			if pending, _ := outbox.Pending(); len(pending) != 0 {
+				t.Errorf("expected the outbox to be empty, got %d", len(pending))
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{7, 30 * time.Minute},
+		{50, 30 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}